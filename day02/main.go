@@ -1,12 +1,9 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
 	"go.uber.org/zap"
-	"os"
-	"strconv"
-	"strings"
 )
 
 var (
@@ -16,46 +13,28 @@ var (
 )
 
 func main() {
-	f, err := os.Open("input.txt")
+	program, err := intcode.LoadProgram("input.txt")
 	if err != nil {
-		log.Fatal("failed to open file", zap.Error(err))
+		log.Fatal("failed to load the program", zap.Error(err))
 	}
-	defer func() {
-		if err := f.Close(); err != nil {
-			log.Warn("failed to close", zap.Error(err))
-		}
-	}()
-
-	input := bufio.NewScanner(f)
-	input.Scan()
-	line := input.Text()
-	logSugar.Debugf("the input %s", line)
-
-	program := readProgram(line)
 	program[1] = 12
 	program[2] = 2
-	//program := readProgram("1,0,0,0,99")
-	//program := readProgram("2,3,0,3,99")
-	//program := readProgram("2,4,4,5,99,0")
-	//program := readProgram("1,1,1,4,99,5,6,0,99")
 
 	logSugar.Info("Program", program)
 
 	result := run(program)
 	logSugar.Info("Resulting", result)
 
-	for noun := 0; noun <= 99; noun++ {
-		for verb := 0; verb <= 99; verb++ {
-			program[1] = noun
-			program[2] = verb
-			result = run(program)
-			if result[0] == 19690720 {
-				fmt.Printf("Solution found noun=%d verb=%d %d\n", noun, verb, 100*noun+verb)
-				return
-			}
-		}
+	symbolic := intcode.NewSymbolic(program)
+	symbolic.MemoryVar(1, "noun", 0, 99)
+	symbolic.MemoryVar(2, "verb", 0, 99)
+	symbolic.SetLogger(log)
+	nounVerb, err := symbolic.SolveMemory(0, 19690720)
+	if err != nil {
+		log.Fatal("no solution", zap.Error(err))
 	}
-
+	noun, verb := nounVerb[0], nounVerb[1]
+	fmt.Printf("Solution found noun=%d verb=%d %d\n", noun, verb, 100*noun+verb)
 }
 
 func run(programOriginal []int) []int {
@@ -80,19 +59,3 @@ func run(programOriginal []int) []int {
 
 	return program
 }
-
-func readProgram(str string) []int {
-	var res []int
-	for _, opcode := range strings.Split(str, ",") {
-		res = append(res, MakeAtoi(opcode))
-	}
-	return res
-}
-
-func MakeAtoi(s string) int {
-	res, err := strconv.Atoi(s)
-	if err != nil {
-		panic(err)
-	}
-	return res
-}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"go.uber.org/zap"
 	"os"
@@ -176,6 +178,17 @@ func OpEquals(ip int, memory []int) int {
 
 func main() {
 	//test()
+	tracePath := flag.String("trace", "", "write a JSON record per instruction to `file`, for intcode tracediff")
+	flag.Parse()
+	var trace *json.Encoder
+	if *tracePath != "" {
+		f, err := os.Create(*tracePath)
+		if err != nil {
+			log.Fatal("failed", zap.Error(err))
+		}
+		// unbuffered, main does not return while it waits for more output
+		trace = json.NewEncoder(f)
+	}
 
 	lines, err := readInputFile("input.txt")
 	if err != nil {
@@ -187,22 +200,69 @@ func main() {
 	output := make(chan int, 3000)
 
 	input <- 5
-	solve(memory, input, output)
+	solve(memory, input, output, trace)
 
 	for data := range output {
 		fmt.Printf("Part1: %d\n", data)
 	}
 }
 
-func solve(memoryOriginal []int, input <-chan int, output chan<- int) {
+// traceRecord is a line of the JSONL trace of intcode trace -json
+type traceRecord struct {
+	Step         int          `json:"step"`
+	IP           int          `json:"ip"`
+	Instruction  int          `json:"instruction"`
+	RelativeBase int          `json:"rb"`
+	Writes       []traceWrite `json:"writes,omitempty"`
+	Output       *int         `json:"output,omitempty"`
+}
+
+type traceWrite struct {
+	Address int `json:"address"`
+	Value   int `json:"value"`
+}
+
+// startRecord describes the instruction at ip before it runs, finishRecord adds what it wrote
+func startRecord(step, ip int, memory []int) (traceRecord, int) {
+	r := traceRecord{Step: step, IP: ip, Instruction: memory[ip]}
+	switch memory[ip] % 100 {
+	case ADD, MUL, LESS_THAN, EQUALS:
+		return r, memory[ip+3]
+	case INPUT:
+		return r, memory[ip+1]
+	case OUTPUT:
+		value := memory[ip+1]
+		if (memory[ip] / 100 % 10) == POSITION_MODE {
+			value = memory[value]
+		}
+		r.Output = &value
+	}
+	return r, -1
+}
+
+func finishRecord(trace *json.Encoder, r traceRecord, written int, memory []int) {
+	if written >= 0 {
+		r.Writes = []traceWrite{{Address: written, Value: memory[written]}}
+	}
+	if err := trace.Encode(r); err != nil {
+		log.Fatal("failed to write trace", zap.Error(err))
+	}
+}
+
+func solve(memoryOriginal []int, input <-chan int, output chan<- int, trace *json.Encoder) {
 	log.Debug("solving")
 	memory := append([]int(nil), memoryOriginal...)
 
 	ip := 0
 	stop := false
-	for !stop {
+	for step := 0; !stop; step++ {
 		instruction := (memory[ip]/10)%10*10 + (memory[ip] % 10)
 		logSugar.Debug("Processing ip ", ip, memory[ip])
+		var record traceRecord
+		written := -1
+		if trace != nil {
+			record, written = startRecord(step, ip, memory)
+		}
 		switch instruction {
 		case ADD:
 			ip = Add(ip, memory)
@@ -225,6 +285,9 @@ func solve(memoryOriginal []int, input <-chan int, output chan<- int) {
 		default:
 			log.Fatal("Failed")
 		}
+		if trace != nil {
+			finishRecord(trace, record, written, memory)
+		}
 	}
 	log.Debug("Memory", zap.Ints("memory", memory))
 }
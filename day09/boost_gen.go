@@ -0,0 +1,1766 @@
+// Code generated by intcode transpile. DO NOT EDIT.
+
+package main
+
+import "github.com/ljdelight/adventOfCode-2019/intcode"
+
+// boost is a transpiled program, run it with intcode.Computer.RunNative
+var boost = &intcode.Compiled{
+	Program: []int{1102, 34463338, 34463338, 63, 1007, 63, 34463338, 63, 1005, 63, 53, 1101, 3, 0, 1000, 109, 988, 209, 12, 9, 1000, 209, 6, 209, 3, 203, 0, 1008, 1000, 1, 63, 1005, 63, 65, 1008, 1000, 2, 63, 1005, 63, 904, 1008, 1000, 0, 63, 1005, 63, 58, 4, 25, 104, 0, 99, 4, 0, 104, 0, 99, 4, 17, 104, 0, 99, 0, 0, 1101, 37, 0, 1005, 1101, 30, 0, 1013, 1102, 1, 33, 1019, 1102, 1, 25, 1003, 1102, 1, 28, 1018, 1101, 26, 0, 1006, 1102, 1, 866, 1029, 1101, 760, 0, 1023, 1102, 39, 1, 1012, 1102, 23, 1, 1009, 1101, 281, 0, 1026, 1102, 1, 20, 1011, 1102, 1, 34, 1008, 1101, 0, 36, 1017, 1101, 38, 0, 1000, 1102, 0, 1, 1020, 1102, 278, 1, 1027, 1101, 21, 0, 1010, 1102, 875, 1, 1028, 1101, 0, 212, 1025, 1102, 1, 1, 1021, 1102, 1, 24, 1014, 1102, 763, 1, 1022, 1101, 0, 31, 1007, 1102, 1, 221, 1024, 1101, 0, 32, 1002, 1102, 1, 29, 1004, 1102, 1, 35, 1016, 1102, 22, 1, 1015, 1101, 0, 27, 1001, 109, 9, 1207, -6, 26, 63, 1005, 63, 199, 4, 187, 1105, 1, 203, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, 19, 2105, 1, -4, 4, 209, 1001, 64, 1, 64, 1106, 0, 221, 1002, 64, 2, 64, 109, -33, 1207, 5, 37, 63, 1005, 63, 241, 1001, 64, 1, 64, 1106, 0, 243, 4, 227, 1002, 64, 2, 64, 109, 16, 2102, 1, -2, 63, 1008, 63, 23, 63, 1005, 63, 269, 4, 249, 1001, 64, 1, 64, 1106, 0, 269, 1002, 64, 2, 64, 109, 16, 2106, 0, 0, 1106, 0, 287, 4, 275, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, -11, 21101, 40, 0, 0, 1008, 1016, 38, 63, 1005, 63, 311, 1001, 64, 1, 64, 1105, 1, 313, 4, 293, 1002, 64, 2, 64, 109, 4, 21107, 41, 40, -9, 1005, 1011, 329, 1105, 1, 335, 4, 319, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, -14, 21108, 42, 42, 5, 1005, 1011, 353, 4, 341, 1106, 0, 357, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, 2, 2107, 33, 0, 63, 1005, 63, 379, 4, 363, 1001, 64, 1, 64, 1105, 1, 379, 1002, 64, 2, 64, 109, -7, 1201, 2, 0, 63, 1008, 63, 25, 63, 1005, 63, 401, 4, 385, 1105, 1, 405, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, 11, 1201, -8, 0, 63, 1008, 63, 28, 63, 1005, 63, 429, 1001, 64, 1, 64, 1106, 0, 431, 4, 411, 1002, 64, 2, 64, 109, -7, 2108, 26, 1, 63, 1005, 63, 449, 4, 437, 1105, 1, 453, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, 9, 1206, 7, 465, 1105, 1, 471, 4, 459, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, 4, 21102, 43, 1, -3, 1008, 1015, 42, 63, 1005, 63, 491, 1106, 0, 497, 4, 477, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, 7, 21108, 44, 43, -7, 1005, 1018, 517, 1001, 64, 1, 64, 1105, 1, 519, 4, 503, 1002, 64, 2, 64, 109, -28, 2101, 0, 7, 63, 1008, 63, 29, 63, 1005, 63, 545, 4, 525, 1001, 64, 1, 64, 1105, 1, 545, 1002, 64, 2, 64, 109, 11, 2107, 28, -7, 63, 1005, 63, 561, 1105, 1, 567, 4, 551, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, -4, 2101, 0, -1, 63, 1008, 63, 26, 63, 1005, 63, 587, 1105, 1, 593, 4, 573, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, 9, 1206, 7, 607, 4, 599, 1105, 1, 611, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, -10, 1208, 1, 27, 63, 1005, 63, 627, 1106, 0, 633, 4, 617, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, 26, 1205, -9, 649, 1001, 64, 1, 64, 1106, 0, 651, 4, 639, 1002, 64, 2, 64, 109, -20, 1208, 0, 23, 63, 1005, 63, 669, 4, 657, 1105, 1, 673, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, -7, 2102, 1, 1, 63, 1008, 63, 28, 63, 1005, 63, 693, 1105, 1, 699, 4, 679, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, 18, 21102, 45, 1, -6, 1008, 1014, 45, 63, 1005, 63, 725, 4, 705, 1001, 64, 1, 64, 1106, 0, 725, 1002, 64, 2, 64, 109, -23, 1202, 6, 1, 63, 1008, 63, 25, 63, 1005, 63, 751, 4, 731, 1001, 64, 1, 64, 1106, 0, 751, 1002, 64, 2, 64, 109, 20, 2105, 1, 6, 1106, 0, 769, 4, 757, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, -22, 2108, 39, 10, 63, 1005, 63, 789, 1001, 64, 1, 64, 1106, 0, 791, 4, 775, 1002, 64, 2, 64, 109, 3, 1202, 6, 1, 63, 1008, 63, 32, 63, 1005, 63, 815, 1001, 64, 1, 64, 1105, 1, 817, 4, 797, 1002, 64, 2, 64, 109, 23, 21107, 46, 47, -9, 1005, 1012, 835, 4, 823, 1106, 0, 839, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, 1, 1205, -1, 853, 4, 845, 1105, 1, 857, 1001, 64, 1, 64, 1002, 64, 2, 64, 109, -2, 2106, 0, 8, 4, 863, 1001, 64, 1, 64, 1105, 1, 875, 1002, 64, 2, 64, 109, -8, 21101, 47, 0, -2, 1008, 1010, 47, 63, 1005, 63, 897, 4, 881, 1106, 0, 901, 1001, 64, 1, 64, 4, 64, 99, 21102, 27, 1, 1, 21101, 0, 915, 0, 1105, 1, 922, 21201, 1, 27810, 1, 204, 1, 99, 109, 3, 1207, -2, 3, 63, 1005, 63, 964, 21201, -2, -1, 1, 21102, 1, 942, 0, 1106, 0, 922, 22101, 0, 1, -1, 21201, -2, -3, 1, 21101, 957, 0, 0, 1106, 0, 922, 22201, 1, -1, -2, 1106, 0, 968, 22101, 0, -2, -2, 109, -3, 2106, 0, 0},
+	Code:    boostCode,
+	Run:     boostRun,
+}
+
+var boostCode = []bool{true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, false, false, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true}
+
+func boostRun(n *intcode.Native) {
+	mem, ip, rb := n.Mem, n.IP, n.RB
+	code := boostCode
+	var a, v int
+	_, _, _ = a, v, code
+dispatch:
+	switch ip {
+	case 0:
+		goto L0
+	case 4:
+		goto L4
+	case 8:
+		goto L8
+	case 11:
+		goto L11
+	case 15:
+		goto L15
+	case 17:
+		goto L17
+	case 19:
+		goto L19
+	case 21:
+		goto L21
+	case 23:
+		goto L23
+	case 25:
+		goto L25
+	case 27:
+		goto L27
+	case 31:
+		goto L31
+	case 34:
+		goto L34
+	case 38:
+		goto L38
+	case 41:
+		goto L41
+	case 45:
+		goto L45
+	case 48:
+		goto L48
+	case 50:
+		goto L50
+	case 52:
+		goto L52
+	case 53:
+		goto L53
+	case 55:
+		goto L55
+	case 57:
+		goto L57
+	case 58:
+		goto L58
+	case 60:
+		goto L60
+	case 62:
+		goto L62
+	case 65:
+		goto L65
+	case 69:
+		goto L69
+	case 73:
+		goto L73
+	case 77:
+		goto L77
+	case 81:
+		goto L81
+	case 85:
+		goto L85
+	case 89:
+		goto L89
+	case 93:
+		goto L93
+	case 97:
+		goto L97
+	case 101:
+		goto L101
+	case 105:
+		goto L105
+	case 109:
+		goto L109
+	case 113:
+		goto L113
+	case 117:
+		goto L117
+	case 121:
+		goto L121
+	case 125:
+		goto L125
+	case 129:
+		goto L129
+	case 133:
+		goto L133
+	case 137:
+		goto L137
+	case 141:
+		goto L141
+	case 145:
+		goto L145
+	case 149:
+		goto L149
+	case 153:
+		goto L153
+	case 157:
+		goto L157
+	case 161:
+		goto L161
+	case 165:
+		goto L165
+	case 169:
+		goto L169
+	case 173:
+		goto L173
+	case 177:
+		goto L177
+	case 181:
+		goto L181
+	case 185:
+		goto L185
+	case 187:
+		goto L187
+	case 191:
+		goto L191
+	case 194:
+		goto L194
+	case 196:
+		goto L196
+	case 199:
+		goto L199
+	case 203:
+		goto L203
+	case 207:
+		goto L207
+	case 209:
+		goto L209
+	case 212:
+		goto L212
+	case 214:
+		goto L214
+	case 218:
+		goto L218
+	case 221:
+		goto L221
+	case 225:
+		goto L225
+	case 227:
+		goto L227
+	case 231:
+		goto L231
+	case 234:
+		goto L234
+	case 238:
+		goto L238
+	case 241:
+		goto L241
+	case 243:
+		goto L243
+	case 247:
+		goto L247
+	case 249:
+		goto L249
+	case 253:
+		goto L253
+	case 257:
+		goto L257
+	case 260:
+		goto L260
+	case 262:
+		goto L262
+	case 266:
+		goto L266
+	case 269:
+		goto L269
+	case 273:
+		goto L273
+	case 275:
+		goto L275
+	case 278:
+		goto L278
+	case 281:
+		goto L281
+	case 283:
+		goto L283
+	case 287:
+		goto L287
+	case 291:
+		goto L291
+	case 293:
+		goto L293
+	case 297:
+		goto L297
+	case 301:
+		goto L301
+	case 304:
+		goto L304
+	case 308:
+		goto L308
+	case 311:
+		goto L311
+	case 313:
+		goto L313
+	case 317:
+		goto L317
+	case 319:
+		goto L319
+	case 323:
+		goto L323
+	case 326:
+		goto L326
+	case 329:
+		goto L329
+	case 331:
+		goto L331
+	case 335:
+		goto L335
+	case 339:
+		goto L339
+	case 341:
+		goto L341
+	case 345:
+		goto L345
+	case 348:
+		goto L348
+	case 350:
+		goto L350
+	case 353:
+		goto L353
+	case 357:
+		goto L357
+	case 361:
+		goto L361
+	case 363:
+		goto L363
+	case 367:
+		goto L367
+	case 370:
+		goto L370
+	case 372:
+		goto L372
+	case 376:
+		goto L376
+	case 379:
+		goto L379
+	case 383:
+		goto L383
+	case 385:
+		goto L385
+	case 389:
+		goto L389
+	case 393:
+		goto L393
+	case 396:
+		goto L396
+	case 398:
+		goto L398
+	case 401:
+		goto L401
+	case 405:
+		goto L405
+	case 409:
+		goto L409
+	case 411:
+		goto L411
+	case 415:
+		goto L415
+	case 419:
+		goto L419
+	case 422:
+		goto L422
+	case 426:
+		goto L426
+	case 429:
+		goto L429
+	case 431:
+		goto L431
+	case 435:
+		goto L435
+	case 437:
+		goto L437
+	case 441:
+		goto L441
+	case 444:
+		goto L444
+	case 446:
+		goto L446
+	case 449:
+		goto L449
+	case 453:
+		goto L453
+	case 457:
+		goto L457
+	case 459:
+		goto L459
+	case 462:
+		goto L462
+	case 465:
+		goto L465
+	case 467:
+		goto L467
+	case 471:
+		goto L471
+	case 475:
+		goto L475
+	case 477:
+		goto L477
+	case 481:
+		goto L481
+	case 485:
+		goto L485
+	case 488:
+		goto L488
+	case 491:
+		goto L491
+	case 493:
+		goto L493
+	case 497:
+		goto L497
+	case 501:
+		goto L501
+	case 503:
+		goto L503
+	case 507:
+		goto L507
+	case 510:
+		goto L510
+	case 514:
+		goto L514
+	case 517:
+		goto L517
+	case 519:
+		goto L519
+	case 523:
+		goto L523
+	case 525:
+		goto L525
+	case 529:
+		goto L529
+	case 533:
+		goto L533
+	case 536:
+		goto L536
+	case 538:
+		goto L538
+	case 542:
+		goto L542
+	case 545:
+		goto L545
+	case 549:
+		goto L549
+	case 551:
+		goto L551
+	case 555:
+		goto L555
+	case 558:
+		goto L558
+	case 561:
+		goto L561
+	case 563:
+		goto L563
+	case 567:
+		goto L567
+	case 571:
+		goto L571
+	case 573:
+		goto L573
+	case 577:
+		goto L577
+	case 581:
+		goto L581
+	case 584:
+		goto L584
+	case 587:
+		goto L587
+	case 589:
+		goto L589
+	case 593:
+		goto L593
+	case 597:
+		goto L597
+	case 599:
+		goto L599
+	case 602:
+		goto L602
+	case 604:
+		goto L604
+	case 607:
+		goto L607
+	case 611:
+		goto L611
+	case 615:
+		goto L615
+	case 617:
+		goto L617
+	case 621:
+		goto L621
+	case 624:
+		goto L624
+	case 627:
+		goto L627
+	case 629:
+		goto L629
+	case 633:
+		goto L633
+	case 637:
+		goto L637
+	case 639:
+		goto L639
+	case 642:
+		goto L642
+	case 646:
+		goto L646
+	case 649:
+		goto L649
+	case 651:
+		goto L651
+	case 655:
+		goto L655
+	case 657:
+		goto L657
+	case 661:
+		goto L661
+	case 664:
+		goto L664
+	case 666:
+		goto L666
+	case 669:
+		goto L669
+	case 673:
+		goto L673
+	case 677:
+		goto L677
+	case 679:
+		goto L679
+	case 683:
+		goto L683
+	case 687:
+		goto L687
+	case 690:
+		goto L690
+	case 693:
+		goto L693
+	case 695:
+		goto L695
+	case 699:
+		goto L699
+	case 703:
+		goto L703
+	case 705:
+		goto L705
+	case 709:
+		goto L709
+	case 713:
+		goto L713
+	case 716:
+		goto L716
+	case 718:
+		goto L718
+	case 722:
+		goto L722
+	case 725:
+		goto L725
+	case 729:
+		goto L729
+	case 731:
+		goto L731
+	case 735:
+		goto L735
+	case 739:
+		goto L739
+	case 742:
+		goto L742
+	case 744:
+		goto L744
+	case 748:
+		goto L748
+	case 751:
+		goto L751
+	case 755:
+		goto L755
+	case 757:
+		goto L757
+	case 760:
+		goto L760
+	case 763:
+		goto L763
+	case 765:
+		goto L765
+	case 769:
+		goto L769
+	case 773:
+		goto L773
+	case 775:
+		goto L775
+	case 779:
+		goto L779
+	case 782:
+		goto L782
+	case 786:
+		goto L786
+	case 789:
+		goto L789
+	case 791:
+		goto L791
+	case 795:
+		goto L795
+	case 797:
+		goto L797
+	case 801:
+		goto L801
+	case 805:
+		goto L805
+	case 808:
+		goto L808
+	case 812:
+		goto L812
+	case 815:
+		goto L815
+	case 817:
+		goto L817
+	case 821:
+		goto L821
+	case 823:
+		goto L823
+	case 827:
+		goto L827
+	case 830:
+		goto L830
+	case 832:
+		goto L832
+	case 835:
+		goto L835
+	case 839:
+		goto L839
+	case 843:
+		goto L843
+	case 845:
+		goto L845
+	case 848:
+		goto L848
+	case 850:
+		goto L850
+	case 853:
+		goto L853
+	case 857:
+		goto L857
+	case 861:
+		goto L861
+	case 863:
+		goto L863
+	case 866:
+		goto L866
+	case 868:
+		goto L868
+	case 872:
+		goto L872
+	case 875:
+		goto L875
+	case 879:
+		goto L879
+	case 881:
+		goto L881
+	case 885:
+		goto L885
+	case 889:
+		goto L889
+	case 892:
+		goto L892
+	case 894:
+		goto L894
+	case 897:
+		goto L897
+	case 901:
+		goto L901
+	case 903:
+		goto L903
+	case 904:
+		goto L904
+	case 908:
+		goto L908
+	case 912:
+		goto L912
+	case 915:
+		goto L915
+	case 919:
+		goto L919
+	case 921:
+		goto L921
+	case 922:
+		goto L922
+	case 924:
+		goto L924
+	case 928:
+		goto L928
+	case 931:
+		goto L931
+	case 935:
+		goto L935
+	case 939:
+		goto L939
+	case 942:
+		goto L942
+	case 946:
+		goto L946
+	case 950:
+		goto L950
+	case 954:
+		goto L954
+	case 957:
+		goto L957
+	case 961:
+		goto L961
+	case 964:
+		goto L964
+	case 968:
+		goto L968
+	case 970:
+		goto L970
+	default:
+		n.IP, n.RB = ip, rb
+		return
+	}
+L0: // mul  34463338, 34463338, [63]
+	v = 34463338 * 34463338
+	mem[63] = v
+L4: // lt   [63], 34463338, [63]
+	v = 0
+	if mem[63] < 34463338 {
+		v = 1
+	}
+	mem[63] = v
+L8: // jt   [63], 53
+	if mem[63] != 0 {
+		goto L53
+	}
+L11: // add  3, 0, [1000]
+	v = 3 + 0
+	mem[1000] = v
+L15: // arb  988
+	rb += 988
+L17: // arb  [rb+12]
+	rb += boostLoad(mem, rb+12)
+L19: // arb  [1000]
+	rb += mem[1000]
+L21: // arb  [rb+6]
+	rb += boostLoad(mem, rb+6)
+L23: // arb  [rb+3]
+	rb += boostLoad(mem, rb+3)
+L25: // in   [rb]
+	n.IP, n.RB = 25, rb
+	return
+L27: // eq   [1000], 1, [63]
+	v = 0
+	if mem[1000] == 1 {
+		v = 1
+	}
+	mem[63] = v
+L31: // jt   [63], 65
+	if mem[63] != 0 {
+		goto L65
+	}
+L34: // eq   [1000], 2, [63]
+	v = 0
+	if mem[1000] == 2 {
+		v = 1
+	}
+	mem[63] = v
+L38: // jt   [63], 904
+	if mem[63] != 0 {
+		goto L904
+	}
+L41: // eq   [1000], 0, [63]
+	v = 0
+	if mem[1000] == 0 {
+		v = 1
+	}
+	mem[63] = v
+L45: // jt   [63], 58
+	if mem[63] != 0 {
+		goto L58
+	}
+L48: // out  [25]
+	n.IP, n.RB = 48, rb
+	return
+L50: // out  0
+	n.IP, n.RB = 50, rb
+	return
+L52: // halt
+	n.IP, n.RB = 52, rb
+	return
+L53: // out  [0]
+	n.IP, n.RB = 53, rb
+	return
+L55: // out  0
+	n.IP, n.RB = 55, rb
+	return
+L57: // halt
+	n.IP, n.RB = 57, rb
+	return
+L58: // out  [17]
+	n.IP, n.RB = 58, rb
+	return
+L60: // out  0
+	n.IP, n.RB = 60, rb
+	return
+L62: // halt
+	n.IP, n.RB = 62, rb
+	return
+L65: // add  37, 0, [1005]
+	v = 37 + 0
+	mem[1005] = v
+L69: // add  30, 0, [1013]
+	v = 30 + 0
+	mem[1013] = v
+L73: // mul  1, 33, [1019]
+	v = 1 * 33
+	mem[1019] = v
+L77: // mul  1, 25, [1003]
+	v = 1 * 25
+	mem[1003] = v
+L81: // mul  1, 28, [1018]
+	v = 1 * 28
+	mem[1018] = v
+L85: // add  26, 0, [1006]
+	v = 26 + 0
+	mem[1006] = v
+L89: // mul  1, 866, [1029]
+	v = 1 * 866
+	mem[1029] = v
+L93: // add  760, 0, [1023]
+	v = 760 + 0
+	mem[1023] = v
+L97: // mul  39, 1, [1012]
+	v = 39 * 1
+	mem[1012] = v
+L101: // mul  23, 1, [1009]
+	v = 23 * 1
+	mem[1009] = v
+L105: // add  281, 0, [1026]
+	v = 281 + 0
+	mem[1026] = v
+L109: // mul  1, 20, [1011]
+	v = 1 * 20
+	mem[1011] = v
+L113: // mul  1, 34, [1008]
+	v = 1 * 34
+	mem[1008] = v
+L117: // add  0, 36, [1017]
+	v = 0 + 36
+	mem[1017] = v
+L121: // add  38, 0, [1000]
+	v = 38 + 0
+	mem[1000] = v
+L125: // mul  0, 1, [1020]
+	v = 0 * 1
+	mem[1020] = v
+L129: // mul  278, 1, [1027]
+	v = 278 * 1
+	mem[1027] = v
+L133: // add  21, 0, [1010]
+	v = 21 + 0
+	mem[1010] = v
+L137: // mul  875, 1, [1028]
+	v = 875 * 1
+	mem[1028] = v
+L141: // add  0, 212, [1025]
+	v = 0 + 212
+	mem[1025] = v
+L145: // mul  1, 1, [1021]
+	v = 1 * 1
+	mem[1021] = v
+L149: // mul  1, 24, [1014]
+	v = 1 * 24
+	mem[1014] = v
+L153: // mul  763, 1, [1022]
+	v = 763 * 1
+	mem[1022] = v
+L157: // add  0, 31, [1007]
+	v = 0 + 31
+	mem[1007] = v
+L161: // mul  1, 221, [1024]
+	v = 1 * 221
+	mem[1024] = v
+L165: // add  0, 32, [1002]
+	v = 0 + 32
+	mem[1002] = v
+L169: // mul  1, 29, [1004]
+	v = 1 * 29
+	mem[1004] = v
+L173: // mul  1, 35, [1016]
+	v = 1 * 35
+	mem[1016] = v
+L177: // mul  22, 1, [1015]
+	v = 22 * 1
+	mem[1015] = v
+L181: // add  0, 27, [1001]
+	v = 0 + 27
+	mem[1001] = v
+L185: // arb  9
+	rb += 9
+L187: // lt   [rb-6], 26, [63]
+	v = 0
+	if boostLoad(mem, rb-6) < 26 {
+		v = 1
+	}
+	mem[63] = v
+L191: // jt   [63], 199
+	if mem[63] != 0 {
+		goto L199
+	}
+L194: // out  [187]
+	n.IP, n.RB = 194, rb
+	return
+L196: // jt   1, 203
+	goto L203
+L199: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L203: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L207: // arb  19
+	rb += 19
+L209: // jt   1, [rb-4]
+	ip = boostLoad(mem, rb-4)
+	goto dispatch
+L212: // out  [209]
+	n.IP, n.RB = 212, rb
+	return
+L214: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L218: // jf   0, 221
+	goto L221
+L221: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L225: // arb  -33
+	rb += -33
+L227: // lt   [rb+5], 37, [63]
+	v = 0
+	if boostLoad(mem, rb+5) < 37 {
+		v = 1
+	}
+	mem[63] = v
+L231: // jt   [63], 241
+	if mem[63] != 0 {
+		goto L241
+	}
+L234: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L238: // jf   0, 243
+	goto L243
+L241: // out  [227]
+	n.IP, n.RB = 241, rb
+	return
+L243: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L247: // arb  16
+	rb += 16
+L249: // mul  1, [rb-2], [63]
+	v = 1 * boostLoad(mem, rb-2)
+	mem[63] = v
+L253: // eq   [63], 23, [63]
+	v = 0
+	if mem[63] == 23 {
+		v = 1
+	}
+	mem[63] = v
+L257: // jt   [63], 269
+	if mem[63] != 0 {
+		goto L269
+	}
+L260: // out  [249]
+	n.IP, n.RB = 260, rb
+	return
+L262: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L266: // jf   0, 269
+	goto L269
+L269: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L273: // arb  16
+	rb += 16
+L275: // jf   0, [rb]
+	ip = boostLoad(mem, rb+0)
+	goto dispatch
+L278: // jf   0, 287
+	goto L287
+L281: // out  [275]
+	n.IP, n.RB = 281, rb
+	return
+L283: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L287: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L291: // arb  -11
+	rb += -11
+L293: // add  40, 0, [rb]
+	v = 40 + 0
+	a = rb + 0
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 297, rb, true
+		return
+	}
+	mem[a] = v
+L297: // eq   [1016], 38, [63]
+	v = 0
+	if mem[1016] == 38 {
+		v = 1
+	}
+	mem[63] = v
+L301: // jt   [63], 311
+	if mem[63] != 0 {
+		goto L311
+	}
+L304: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L308: // jt   1, 313
+	goto L313
+L311: // out  [293]
+	n.IP, n.RB = 311, rb
+	return
+L313: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L317: // arb  4
+	rb += 4
+L319: // lt   41, 40, [rb-9]
+	v = 0
+	if 41 < 40 {
+		v = 1
+	}
+	a = rb - 9
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 323, rb, true
+		return
+	}
+	mem[a] = v
+L323: // jt   [1011], 329
+	if mem[1011] != 0 {
+		goto L329
+	}
+L326: // jt   1, 335
+	goto L335
+L329: // out  [319]
+	n.IP, n.RB = 329, rb
+	return
+L331: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L335: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L339: // arb  -14
+	rb += -14
+L341: // eq   42, 42, [rb+5]
+	v = 0
+	if 42 == 42 {
+		v = 1
+	}
+	a = rb + 5
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 345, rb, true
+		return
+	}
+	mem[a] = v
+L345: // jt   [1011], 353
+	if mem[1011] != 0 {
+		goto L353
+	}
+L348: // out  [341]
+	n.IP, n.RB = 348, rb
+	return
+L350: // jf   0, 357
+	goto L357
+L353: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L357: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L361: // arb  2
+	rb += 2
+L363: // lt   33, [rb], [63]
+	v = 0
+	if 33 < boostLoad(mem, rb+0) {
+		v = 1
+	}
+	mem[63] = v
+L367: // jt   [63], 379
+	if mem[63] != 0 {
+		goto L379
+	}
+L370: // out  [363]
+	n.IP, n.RB = 370, rb
+	return
+L372: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L376: // jt   1, 379
+	goto L379
+L379: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L383: // arb  -7
+	rb += -7
+L385: // add  [rb+2], 0, [63]
+	v = boostLoad(mem, rb+2) + 0
+	mem[63] = v
+L389: // eq   [63], 25, [63]
+	v = 0
+	if mem[63] == 25 {
+		v = 1
+	}
+	mem[63] = v
+L393: // jt   [63], 401
+	if mem[63] != 0 {
+		goto L401
+	}
+L396: // out  [385]
+	n.IP, n.RB = 396, rb
+	return
+L398: // jt   1, 405
+	goto L405
+L401: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L405: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L409: // arb  11
+	rb += 11
+L411: // add  [rb-8], 0, [63]
+	v = boostLoad(mem, rb-8) + 0
+	mem[63] = v
+L415: // eq   [63], 28, [63]
+	v = 0
+	if mem[63] == 28 {
+		v = 1
+	}
+	mem[63] = v
+L419: // jt   [63], 429
+	if mem[63] != 0 {
+		goto L429
+	}
+L422: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L426: // jf   0, 431
+	goto L431
+L429: // out  [411]
+	n.IP, n.RB = 429, rb
+	return
+L431: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L435: // arb  -7
+	rb += -7
+L437: // eq   26, [rb+1], [63]
+	v = 0
+	if 26 == boostLoad(mem, rb+1) {
+		v = 1
+	}
+	mem[63] = v
+L441: // jt   [63], 449
+	if mem[63] != 0 {
+		goto L449
+	}
+L444: // out  [437]
+	n.IP, n.RB = 444, rb
+	return
+L446: // jt   1, 453
+	goto L453
+L449: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L453: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L457: // arb  9
+	rb += 9
+L459: // jf   [rb+7], 465
+	if boostLoad(mem, rb+7) == 0 {
+		goto L465
+	}
+L462: // jt   1, 471
+	goto L471
+L465: // out  [459]
+	n.IP, n.RB = 465, rb
+	return
+L467: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L471: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L475: // arb  4
+	rb += 4
+L477: // mul  43, 1, [rb-3]
+	v = 43 * 1
+	a = rb - 3
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 481, rb, true
+		return
+	}
+	mem[a] = v
+L481: // eq   [1015], 42, [63]
+	v = 0
+	if mem[1015] == 42 {
+		v = 1
+	}
+	mem[63] = v
+L485: // jt   [63], 491
+	if mem[63] != 0 {
+		goto L491
+	}
+L488: // jf   0, 497
+	goto L497
+L491: // out  [477]
+	n.IP, n.RB = 491, rb
+	return
+L493: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L497: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L501: // arb  7
+	rb += 7
+L503: // eq   44, 43, [rb-7]
+	v = 0
+	if 44 == 43 {
+		v = 1
+	}
+	a = rb - 7
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 507, rb, true
+		return
+	}
+	mem[a] = v
+L507: // jt   [1018], 517
+	if mem[1018] != 0 {
+		goto L517
+	}
+L510: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L514: // jt   1, 519
+	goto L519
+L517: // out  [503]
+	n.IP, n.RB = 517, rb
+	return
+L519: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L523: // arb  -28
+	rb += -28
+L525: // add  0, [rb+7], [63]
+	v = 0 + boostLoad(mem, rb+7)
+	mem[63] = v
+L529: // eq   [63], 29, [63]
+	v = 0
+	if mem[63] == 29 {
+		v = 1
+	}
+	mem[63] = v
+L533: // jt   [63], 545
+	if mem[63] != 0 {
+		goto L545
+	}
+L536: // out  [525]
+	n.IP, n.RB = 536, rb
+	return
+L538: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L542: // jt   1, 545
+	goto L545
+L545: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L549: // arb  11
+	rb += 11
+L551: // lt   28, [rb-7], [63]
+	v = 0
+	if 28 < boostLoad(mem, rb-7) {
+		v = 1
+	}
+	mem[63] = v
+L555: // jt   [63], 561
+	if mem[63] != 0 {
+		goto L561
+	}
+L558: // jt   1, 567
+	goto L567
+L561: // out  [551]
+	n.IP, n.RB = 561, rb
+	return
+L563: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L567: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L571: // arb  -4
+	rb += -4
+L573: // add  0, [rb-1], [63]
+	v = 0 + boostLoad(mem, rb-1)
+	mem[63] = v
+L577: // eq   [63], 26, [63]
+	v = 0
+	if mem[63] == 26 {
+		v = 1
+	}
+	mem[63] = v
+L581: // jt   [63], 587
+	if mem[63] != 0 {
+		goto L587
+	}
+L584: // jt   1, 593
+	goto L593
+L587: // out  [573]
+	n.IP, n.RB = 587, rb
+	return
+L589: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L593: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L597: // arb  9
+	rb += 9
+L599: // jf   [rb+7], 607
+	if boostLoad(mem, rb+7) == 0 {
+		goto L607
+	}
+L602: // out  [599]
+	n.IP, n.RB = 602, rb
+	return
+L604: // jt   1, 611
+	goto L611
+L607: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L611: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L615: // arb  -10
+	rb += -10
+L617: // eq   [rb+1], 27, [63]
+	v = 0
+	if boostLoad(mem, rb+1) == 27 {
+		v = 1
+	}
+	mem[63] = v
+L621: // jt   [63], 627
+	if mem[63] != 0 {
+		goto L627
+	}
+L624: // jf   0, 633
+	goto L633
+L627: // out  [617]
+	n.IP, n.RB = 627, rb
+	return
+L629: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L633: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L637: // arb  26
+	rb += 26
+L639: // jt   [rb-9], 649
+	if boostLoad(mem, rb-9) != 0 {
+		goto L649
+	}
+L642: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L646: // jf   0, 651
+	goto L651
+L649: // out  [639]
+	n.IP, n.RB = 649, rb
+	return
+L651: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L655: // arb  -20
+	rb += -20
+L657: // eq   [rb], 23, [63]
+	v = 0
+	if boostLoad(mem, rb+0) == 23 {
+		v = 1
+	}
+	mem[63] = v
+L661: // jt   [63], 669
+	if mem[63] != 0 {
+		goto L669
+	}
+L664: // out  [657]
+	n.IP, n.RB = 664, rb
+	return
+L666: // jt   1, 673
+	goto L673
+L669: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L673: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L677: // arb  -7
+	rb += -7
+L679: // mul  1, [rb+1], [63]
+	v = 1 * boostLoad(mem, rb+1)
+	mem[63] = v
+L683: // eq   [63], 28, [63]
+	v = 0
+	if mem[63] == 28 {
+		v = 1
+	}
+	mem[63] = v
+L687: // jt   [63], 693
+	if mem[63] != 0 {
+		goto L693
+	}
+L690: // jt   1, 699
+	goto L699
+L693: // out  [679]
+	n.IP, n.RB = 693, rb
+	return
+L695: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L699: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L703: // arb  18
+	rb += 18
+L705: // mul  45, 1, [rb-6]
+	v = 45 * 1
+	a = rb - 6
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 709, rb, true
+		return
+	}
+	mem[a] = v
+L709: // eq   [1014], 45, [63]
+	v = 0
+	if mem[1014] == 45 {
+		v = 1
+	}
+	mem[63] = v
+L713: // jt   [63], 725
+	if mem[63] != 0 {
+		goto L725
+	}
+L716: // out  [705]
+	n.IP, n.RB = 716, rb
+	return
+L718: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L722: // jf   0, 725
+	goto L725
+L725: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L729: // arb  -23
+	rb += -23
+L731: // mul  [rb+6], 1, [63]
+	v = boostLoad(mem, rb+6) * 1
+	mem[63] = v
+L735: // eq   [63], 25, [63]
+	v = 0
+	if mem[63] == 25 {
+		v = 1
+	}
+	mem[63] = v
+L739: // jt   [63], 751
+	if mem[63] != 0 {
+		goto L751
+	}
+L742: // out  [731]
+	n.IP, n.RB = 742, rb
+	return
+L744: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L748: // jf   0, 751
+	goto L751
+L751: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L755: // arb  20
+	rb += 20
+L757: // jt   1, [rb+6]
+	ip = boostLoad(mem, rb+6)
+	goto dispatch
+L760: // jf   0, 769
+	goto L769
+L763: // out  [757]
+	n.IP, n.RB = 763, rb
+	return
+L765: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L769: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L773: // arb  -22
+	rb += -22
+L775: // eq   39, [rb+10], [63]
+	v = 0
+	if 39 == boostLoad(mem, rb+10) {
+		v = 1
+	}
+	mem[63] = v
+L779: // jt   [63], 789
+	if mem[63] != 0 {
+		goto L789
+	}
+L782: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L786: // jf   0, 791
+	goto L791
+L789: // out  [775]
+	n.IP, n.RB = 789, rb
+	return
+L791: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L795: // arb  3
+	rb += 3
+L797: // mul  [rb+6], 1, [63]
+	v = boostLoad(mem, rb+6) * 1
+	mem[63] = v
+L801: // eq   [63], 32, [63]
+	v = 0
+	if mem[63] == 32 {
+		v = 1
+	}
+	mem[63] = v
+L805: // jt   [63], 815
+	if mem[63] != 0 {
+		goto L815
+	}
+L808: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L812: // jt   1, 817
+	goto L817
+L815: // out  [797]
+	n.IP, n.RB = 815, rb
+	return
+L817: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L821: // arb  23
+	rb += 23
+L823: // lt   46, 47, [rb-9]
+	v = 0
+	if 46 < 47 {
+		v = 1
+	}
+	a = rb - 9
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 827, rb, true
+		return
+	}
+	mem[a] = v
+L827: // jt   [1012], 835
+	if mem[1012] != 0 {
+		goto L835
+	}
+L830: // out  [823]
+	n.IP, n.RB = 830, rb
+	return
+L832: // jf   0, 839
+	goto L839
+L835: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L839: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L843: // arb  1
+	rb += 1
+L845: // jt   [rb-1], 853
+	if boostLoad(mem, rb-1) != 0 {
+		goto L853
+	}
+L848: // out  [845]
+	n.IP, n.RB = 848, rb
+	return
+L850: // jt   1, 857
+	goto L857
+L853: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L857: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L861: // arb  -2
+	rb += -2
+L863: // jf   0, [rb+8]
+	ip = boostLoad(mem, rb+8)
+	goto dispatch
+L866: // out  [863]
+	n.IP, n.RB = 866, rb
+	return
+L868: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L872: // jt   1, 875
+	goto L875
+L875: // mul  [64], 2, [64]
+	v = mem[64] * 2
+	mem[64] = v
+L879: // arb  -8
+	rb += -8
+L881: // add  47, 0, [rb-2]
+	v = 47 + 0
+	a = rb - 2
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 885, rb, true
+		return
+	}
+	mem[a] = v
+L885: // eq   [1010], 47, [63]
+	v = 0
+	if mem[1010] == 47 {
+		v = 1
+	}
+	mem[63] = v
+L889: // jt   [63], 897
+	if mem[63] != 0 {
+		goto L897
+	}
+L892: // out  [881]
+	n.IP, n.RB = 892, rb
+	return
+L894: // jf   0, 901
+	goto L901
+L897: // add  [64], 1, [64]
+	v = mem[64] + 1
+	mem[64] = v
+L901: // out  [64]
+	n.IP, n.RB = 901, rb
+	return
+L903: // halt
+	n.IP, n.RB = 903, rb
+	return
+L904: // mul  27, 1, [rb+1]
+	v = 27 * 1
+	a = rb + 1
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 908, rb, true
+		return
+	}
+	mem[a] = v
+L908: // add  0, 915, [rb]
+	v = 0 + 915
+	a = rb + 0
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 912, rb, true
+		return
+	}
+	mem[a] = v
+L912: // jt   1, 922
+	goto L922
+L915: // add  [rb+1], 27810, [rb+1]
+	v = boostLoad(mem, rb+1) + 27810
+	a = rb + 1
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 919, rb, true
+		return
+	}
+	mem[a] = v
+L919: // out  [rb+1]
+	n.IP, n.RB = 919, rb
+	return
+L921: // halt
+	n.IP, n.RB = 921, rb
+	return
+L922: // arb  3
+	rb += 3
+L924: // lt   [rb-2], 3, [63]
+	v = 0
+	if boostLoad(mem, rb-2) < 3 {
+		v = 1
+	}
+	mem[63] = v
+L928: // jt   [63], 964
+	if mem[63] != 0 {
+		goto L964
+	}
+L931: // add  [rb-2], -1, [rb+1]
+	v = boostLoad(mem, rb-2) + -1
+	a = rb + 1
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 935, rb, true
+		return
+	}
+	mem[a] = v
+L935: // mul  1, 942, [rb]
+	v = 1 * 942
+	a = rb + 0
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 939, rb, true
+		return
+	}
+	mem[a] = v
+L939: // jf   0, 922
+	goto L922
+L942: // add  0, [rb+1], [rb-1]
+	v = 0 + boostLoad(mem, rb+1)
+	a = rb - 1
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 946, rb, true
+		return
+	}
+	mem[a] = v
+L946: // add  [rb-2], -3, [rb+1]
+	v = boostLoad(mem, rb-2) + -3
+	a = rb + 1
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 950, rb, true
+		return
+	}
+	mem[a] = v
+L950: // add  957, 0, [rb]
+	v = 957 + 0
+	a = rb + 0
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 954, rb, true
+		return
+	}
+	mem[a] = v
+L954: // jf   0, 922
+	goto L922
+L957: // add  [rb+1], [rb-1], [rb-2]
+	v = boostLoad(mem, rb+1) + boostLoad(mem, rb-1)
+	a = rb - 2
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 961, rb, true
+		return
+	}
+	mem[a] = v
+L961: // jf   0, 968
+	goto L968
+L964: // add  0, [rb-2], [rb-2]
+	v = 0 + boostLoad(mem, rb-2)
+	a = rb - 2
+	if a >= len(mem) {
+		mem = n.Grow(a)
+	}
+	if a < len(code) && code[a] && mem[a] != v {
+		mem[a] = v
+		n.IP, n.RB, n.Modified = 968, rb, true
+		return
+	}
+	mem[a] = v
+L968: // arb  -3
+	rb += -3
+L970: // jf   0, [rb]
+	ip = boostLoad(mem, rb+0)
+	goto dispatch
+}
+
+func boostLoad(mem []int, a int) int {
+	if a < len(mem) {
+		return mem[a]
+	}
+	return 0
+}
@@ -2,4 +2,9 @@ module github.com/ljdelight/adventOfCode-2019/day02
 
 go 1.13
 
-require go.uber.org/zap v1.13.0
+require (
+	github.com/ljdelight/adventOfCode-2019/intcode v0.0.0
+	go.uber.org/zap v1.13.0
+)
+
+replace github.com/ljdelight/adventOfCode-2019/intcode => ../intcode
@@ -0,0 +1,399 @@
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The assembler syntax has one instruction per line with comma separated operands. A bare operand is an
+// immediate value, [x] reads memory at x (position mode) and [rb+x] reads memory relative to the relative base:
+//
+//	start:  in   [rb+1]        ; comments start with ';'
+//	        mul  [rb+1], 2, [x]
+//	        out  [x]
+//	        jt   1, start
+//	x:      data 0
+//
+// Operands may use labels with an optional offset, like [x+1]. data emits its values verbatim.
+
+// mnemonics are the assembler names of the opcodes
+var mnemonics = map[int]string{
+	ADD:               "add",
+	MUL:               "mul",
+	INPUT:             "in",
+	OUTPUT:            "out",
+	JMP_IF_TRUE:       "jt",
+	JMP_IF_FALSE:      "jf",
+	LESS_THAN:         "lt",
+	EQUALS:            "eq",
+	ADJ_RELATIVE_BASE: "arb",
+	HALT:              "halt",
+}
+
+// paramCounts are the number of parameters of every opcode
+var paramCounts = map[int]int{
+	ADD:               3,
+	MUL:               3,
+	INPUT:             1,
+	OUTPUT:            1,
+	JMP_IF_TRUE:       2,
+	JMP_IF_FALSE:      2,
+	LESS_THAN:         3,
+	EQUALS:            3,
+	ADJ_RELATIVE_BASE: 1,
+	HALT:              0,
+}
+
+// writesParam returns the index of the parameter an opcode writes to, or -1
+func writesParam(opcode int) int {
+	switch opcode {
+	case ADD, MUL, LESS_THAN, EQUALS:
+		return 2
+	case INPUT:
+		return 0
+	default:
+		return -1
+	}
+}
+
+// Instruction is a decoded instruction
+type Instruction struct {
+	Address int
+	Opcode  int
+	Modes   []int
+	Params  []int
+}
+
+// Len returns the number of memory cells of the instruction
+func (i *Instruction) Len() int {
+	return 1 + len(i.Params)
+}
+
+// Mnemonic returns the assembler name of the opcode
+func (i *Instruction) Mnemonic() string {
+	return mnemonics[i.Opcode]
+}
+
+func (i *Instruction) String() string {
+	if len(i.Params) == 0 {
+		return i.Mnemonic()
+	}
+	operands := make([]string, len(i.Params))
+	for p, param := range i.Params {
+		switch i.Modes[p] {
+		case IMMEDIATE_MODE:
+			operands[p] = strconv.Itoa(param)
+		case POSITION_MODE:
+			operands[p] = fmt.Sprintf("[%d]", param)
+		case RELATIVE_MODE:
+			switch {
+			case param == 0:
+				operands[p] = "[rb]"
+			case param < 0:
+				operands[p] = fmt.Sprintf("[rb%d]", param)
+			default:
+				operands[p] = fmt.Sprintf("[rb+%d]", param)
+			}
+		}
+	}
+	return fmt.Sprintf("%-4s %s", i.Mnemonic(), strings.Join(operands, ", "))
+}
+
+// Decode decodes the instruction at the address. It fails for unknown opcodes and addressing modes and for
+// instructions that run past the end of memory.
+func Decode(memory []int, address int) (*Instruction, error) {
+	if address < 0 || address >= len(memory) {
+		return nil, fmt.Errorf("address %d is outside of memory", address)
+	}
+	value := memory[address]
+	opcode := value % 100
+	count, ok := paramCounts[opcode]
+	if !ok || value < 0 {
+		return nil, fmt.Errorf("instruction %d does not exist at address %d", value, address)
+	}
+	if address+count >= len(memory) {
+		return nil, fmt.Errorf("instruction at address %d runs past the end of memory", address)
+	}
+
+	inst := &Instruction{Address: address, Opcode: opcode, Modes: make([]int, count), Params: make([]int, count)}
+	mode := value / 100
+	for p := 0; p < count; p++ {
+		inst.Modes[p] = mode % 10
+		if inst.Modes[p] > RELATIVE_MODE || (p == writesParam(opcode) && inst.Modes[p] == IMMEDIATE_MODE) {
+			return nil, fmt.Errorf("invalid addressing mode %d at address %d", inst.Modes[p], address)
+		}
+		inst.Params[p] = memory[address+1+p]
+		mode /= 10
+	}
+	if mode != 0 {
+		return nil, fmt.Errorf("invalid addressing modes %d at address %d", value/100, address)
+	}
+	return inst, nil
+}
+
+// SourceMap relates memory addresses to the source lines they were built from
+type SourceMap struct {
+	// Path is the source file, empty for generated listings
+	Path  string
+	lines map[int]int
+	addrs map[int]int
+}
+
+// NewSourceMap creates an empty source map for the source file
+func NewSourceMap(path string) *SourceMap {
+	return &SourceMap{Path: path, lines: make(map[int]int), addrs: make(map[int]int)}
+}
+
+// Add records that the instruction at the address comes from the line. The first address added for a line is where
+// a breakpoint on it stops.
+func (m *SourceMap) Add(address, line int) {
+	m.lines[address] = line
+	if _, ok := m.addrs[line]; !ok {
+		m.addrs[line] = address
+	}
+}
+
+// Line returns the source line of the instruction at the address
+func (m *SourceMap) Line(address int) (int, bool) {
+	line, ok := m.lines[address]
+	return line, ok
+}
+
+// Address returns the first address built from the line
+func (m *SourceMap) Address(line int) (int, bool) {
+	address, ok := m.addrs[line]
+	return address, ok
+}
+
+// Lines returns every line that has code, in ascending order
+func (m *SourceMap) Lines() []int {
+	lines := make([]int, 0, len(m.addrs))
+	for line := range m.addrs {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// Disassemble lists the program in the assembler syntax, one instruction per line. Values that do not decode as an
+// instruction become data lines. The listing assembles back into the same program.
+func Disassemble(program []int) (string, *SourceMap) {
+	var b strings.Builder
+	m := NewSourceMap("")
+	line := 1
+	for address := 0; address < len(program); line++ {
+		var text string
+		size := 1
+		if inst, err := Decode(program, address); err == nil {
+			text = inst.String()
+			size = inst.Len()
+		} else {
+			text = fmt.Sprintf("data %d", program[address])
+		}
+		m.Add(address, line)
+		fmt.Fprintf(&b, "%-32s ; %d\n", text, address)
+		address += size
+	}
+	return b.String(), m
+}
+
+// AsmError reports an invalid line of assembler source
+type AsmError struct {
+	// Line starts at 1
+	Line int
+	Msg  string
+}
+
+func (e *AsmError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// asmLine is an instruction or data directive waiting for its labels to be resolved
+type asmLine struct {
+	line     int
+	address  int
+	opcode   int
+	operands []string
+	data     bool
+}
+
+// Assemble translates assembler source into a program and a source map for it
+func Assemble(r io.Reader, path string) ([]int, *SourceMap, error) {
+	labels := make(map[string]int)
+	var lines []asmLine
+	address := 0
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, ';'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+
+		// labels come first and may share the line with an instruction
+		for {
+			i := strings.IndexByte(text, ':')
+			if i < 0 || strings.ContainsAny(text[:i], " \t,[") {
+				break
+			}
+			name := text[:i]
+			if !isLabel(name) {
+				return nil, nil, &AsmError{lineNo, fmt.Sprintf("invalid label %q", name)}
+			}
+			if _, ok := labels[name]; ok {
+				return nil, nil, &AsmError{lineNo, fmt.Sprintf("label %q defined twice", name)}
+			}
+			labels[name] = address
+			text = strings.TrimSpace(text[i+1:])
+		}
+		if text == "" {
+			continue
+		}
+
+		mnemonic, rest := text, ""
+		if i := strings.IndexAny(text, " \t"); i >= 0 {
+			mnemonic, rest = text[:i], strings.TrimSpace(text[i:])
+		}
+		mnemonic = strings.ToLower(mnemonic)
+		var operands []string
+		if rest != "" {
+			for _, op := range strings.Split(rest, ",") {
+				operands = append(operands, strings.TrimSpace(op))
+			}
+		}
+
+		l := asmLine{line: lineNo, address: address, operands: operands}
+		if mnemonic == "data" {
+			if len(operands) == 0 {
+				return nil, nil, &AsmError{lineNo, "data needs at least one value"}
+			}
+			l.data = true
+			address += len(operands)
+		} else {
+			opcode, ok := opcodeOf(mnemonic)
+			if !ok {
+				return nil, nil, &AsmError{lineNo, fmt.Sprintf("unknown instruction %q", mnemonic)}
+			}
+			if len(operands) != paramCounts[opcode] {
+				return nil, nil, &AsmError{lineNo, fmt.Sprintf("%s takes %d operands, got %d", mnemonic, paramCounts[opcode], len(operands))}
+			}
+			l.opcode = opcode
+			address += 1 + len(operands)
+		}
+		lines = append(lines, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	program := make([]int, 0, address)
+	m := NewSourceMap(path)
+	for _, l := range lines {
+		m.Add(l.address, l.line)
+		if l.data {
+			for _, op := range l.operands {
+				val, err := evalOperand(op, labels)
+				if err != nil {
+					return nil, nil, &AsmError{l.line, err.Error()}
+				}
+				program = append(program, val)
+			}
+			continue
+		}
+
+		instruction := l.opcode
+		var params []int
+		scale := 100
+		for p, op := range l.operands {
+			mode, val, err := parseOperand(op, labels)
+			if err != nil {
+				return nil, nil, &AsmError{l.line, err.Error()}
+			}
+			if p == writesParam(l.opcode) && mode == IMMEDIATE_MODE {
+				return nil, nil, &AsmError{l.line, fmt.Sprintf("operand %d of %s is written and must be a memory reference", p+1, mnemonics[l.opcode])}
+			}
+			instruction += mode * scale
+			scale *= 10
+			params = append(params, val)
+		}
+		program = append(program, instruction)
+		program = append(program, params...)
+	}
+	return program, m, nil
+}
+
+func opcodeOf(mnemonic string) (int, bool) {
+	for opcode, name := range mnemonics {
+		if name == mnemonic {
+			return opcode, true
+		}
+	}
+	return 0, false
+}
+
+func isLabel(name string) bool {
+	if name == "" || name == "rb" {
+		return false
+	}
+	for i, r := range name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseOperand returns the addressing mode and parameter of an operand
+func parseOperand(op string, labels map[string]int) (int, int, error) {
+	if !strings.HasPrefix(op, "[") {
+		val, err := evalOperand(op, labels)
+		return IMMEDIATE_MODE, val, err
+	}
+	if !strings.HasSuffix(op, "]") {
+		return 0, 0, fmt.Errorf("missing ] in %q", op)
+	}
+	inner := strings.TrimSpace(op[1 : len(op)-1])
+	if inner == "rb" {
+		return RELATIVE_MODE, 0, nil
+	}
+	if strings.HasPrefix(inner, "rb") && len(inner) > 2 && (inner[2] == '+' || inner[2] == '-' || inner[2] == ' ') {
+		offset := strings.TrimSpace(inner[2:])
+		sign := 1
+		if strings.HasPrefix(offset, "-") {
+			sign = -1
+		}
+		val, err := evalOperand(strings.TrimSpace(strings.TrimLeft(offset, "+-")), labels)
+		return RELATIVE_MODE, sign * val, err
+	}
+	val, err := evalOperand(inner, labels)
+	return POSITION_MODE, val, err
+}
+
+// evalOperand evaluates a number, a label or a label with an offset
+func evalOperand(expr string, labels map[string]int) (int, error) {
+	expr = strings.TrimSpace(expr)
+	if val, err := strconv.Atoi(expr); err == nil {
+		return val, nil
+	}
+	name, offset := expr, 0
+	if i := strings.LastIndexAny(expr, "+-"); i > 0 {
+		val, err := strconv.Atoi(strings.TrimSpace(expr[i+1:]))
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset in %q", expr)
+		}
+		if expr[i] == '-' {
+			val = -val
+		}
+		name, offset = strings.TrimSpace(expr[:i]), val
+	}
+	address, ok := labels[name]
+	if !ok {
+		return 0, fmt.Errorf("undefined label %q", name)
+	}
+	return address + offset, nil
+}
@@ -0,0 +1,107 @@
+package intcode
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// compare8 is the day 5 example that outputs 999, 1000 or 1001 for an input below, equal to or above 8
+var compare8 = []int{3, 21, 1008, 21, 8, 20, 1005, 20, 22, 107, 8, 21, 20, 1006, 20, 31, 1106, 0, 36, 98, 0, 0, 1002,
+	21, 125, 20, 4, 20, 1105, 1, 46, 104, 999, 1105, 1, 46, 1101, 1000, 1, 20, 4, 20, 1105, 1, 46, 98, 99}
+
+func TestAssembleDisassemble(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+	}{
+		{"day 2", []int{1, 9, 10, 3, 2, 3, 11, 0, 99, 30, 40, 50}},
+		{"day 5 compare to 8", compare8},
+		{"day 9 quine", quine},
+		{"relative modes", []int{109, -3, 204, 0, 21101, 1, -1, 5, 22201, 1, 2, 3, 99}},
+		{"invalid instructions", []int{0, 42, -1, 11101, 1, 1, 1, 304, 5, 1099}},
+		{"instruction past the end", []int{104, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listing, m := Disassemble(tt.program)
+			program, _, err := Assemble(strings.NewReader(listing), "")
+			if err != nil {
+				t.Fatalf("%v in\n%s", err, listing)
+			}
+			if !reflect.DeepEqual(program, tt.program) {
+				t.Errorf("assembled %v, want %v from\n%s", program, tt.program, listing)
+			}
+			// every line of the listing ends with the address it maps to
+			for i, line := range strings.Split(strings.TrimSuffix(listing, "\n"), "\n") {
+				address, ok := m.Address(i + 1)
+				if !ok || !strings.HasSuffix(line, "; "+strconv.Itoa(address)) {
+					t.Errorf("line %d %q maps to address %d", i+1, line, address)
+				}
+			}
+		})
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	source := `
+start:  in   [rb+1]        ; comments start with ';'
+        mul  [rb+1], 2, [x]
+        out  [x]
+        jt   1, start
+x:      data 0
+end:    data x, end-1, -2`
+	program, m, err := Assemble(strings.NewReader(source), "double.ica")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{203, 1, 1202, 1, 2, 11, 4, 11, 1105, 1, 0, 0, 11, 11, -2}
+	if !reflect.DeepEqual(program, want) {
+		t.Errorf("assembled %v, want %v", program, want)
+	}
+	for _, lines := range [][2]int{{0, 2}, {2, 3}, {6, 4}, {8, 5}, {11, 6}, {12, 7}} {
+		if line, ok := m.Line(lines[0]); !ok || line != lines[1] {
+			t.Errorf("address %d maps to line %d, want %d", lines[0], line, lines[1])
+		}
+	}
+
+	c := NewComputer(program)
+	c.Send(21)
+	c.CloseInput()
+	c.Run()
+	if out := c.TakeOutput(); !reflect.DeepEqual(out, []int{42}) {
+		t.Errorf("output %v, want [42]", out)
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		line   int
+	}{
+		{"unknown instruction", "add 1, 2, [3]\nmov 1, [2]", 2},
+		{"operand count", "out 1, 2", 1},
+		{"immediate write", "add 1, 2, 3", 1},
+		{"undefined label", "jt 1, nowhere", 1},
+		{"label defined twice", "a: halt\na: halt", 2},
+		{"invalid label", "1a: halt", 1},
+		{"missing bracket", "\n\nout [rb+1", 3},
+		{"empty data", "data", 1},
+		{"invalid offset", "x: data x+y", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Assemble(strings.NewReader(tt.source), "")
+			var asmErr *AsmError
+			if !errors.As(err, &asmErr) {
+				t.Fatalf("error %v, want an AsmError", err)
+			}
+			if asmErr.Line != tt.line {
+				t.Errorf("error on line %d, want %d: %v", asmErr.Line, tt.line, err)
+			}
+		})
+	}
+}
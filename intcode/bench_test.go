@@ -0,0 +1,131 @@
+package intcode
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// runWords runs the program once on a machine with words of type W and returns its outputs as text
+func runWords[W any, A Arithmetic[W]](program, input []int) ([]string, *Machine[W, A], error) {
+	words, err := Words[W, A](program)
+	if err != nil {
+		return nil, nil, err
+	}
+	in, err := Words[W, A](input)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := NewMachine[W, A](words)
+	m.Send(in...)
+	m.CloseInput()
+	if _, err := m.Run(); err != nil {
+		return nil, m, err
+	}
+	var outputs []string
+	for _, val := range m.TakeOutput() {
+		outputs = append(outputs, fmt.Sprint(val))
+	}
+	return outputs, m, nil
+}
+
+// wordTests are programs for every word type, int32 words overflow on the large values of day 9
+var wordTests = []struct {
+	name    string
+	program []int
+	input   []int
+	output  []string
+	// overflows is set when int32 words fault
+	overflows bool
+}{
+	{"compare to 8", compare8, []int{9}, []string{"1001"}, false},
+	{"quine", quine, nil, []string{"109", "1", "204", "-1", "1001", "100", "1", "100", "1008", "100", "16", "101",
+		"1006", "101", "0", "99"}, false},
+	{"large product", []int{1102, 34915192, 34915192, 7, 4, 7, 99, 0}, nil, []string{"1219070632396864"}, true},
+}
+
+func TestWords(t *testing.T) {
+	for _, tt := range wordTests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := func(word string, output []string, err error, overflows bool) {
+				t.Helper()
+				var overflow *OverflowError
+				if overflows {
+					if !errors.As(err, &overflow) {
+						t.Errorf("%s words: error %v, want an overflow", word, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("%s words: %v", word, err)
+				}
+				if !reflect.DeepEqual(output, tt.output) {
+					t.Errorf("%s words: output %v, want %v", word, output, tt.output)
+				}
+			}
+			output, _, err := runWords[int, IntWords](tt.program, tt.input)
+			check("int", output, err, false)
+			output, _, err = runWords[int64, Int64Words](tt.program, tt.input)
+			check("int64", output, err, false)
+			output, _, err = runWords[*big.Int, BigWords](tt.program, tt.input)
+			check("big", output, err, false)
+			output, _, err = runWords[int32, Int32Words](tt.program, tt.input)
+			check("int32", output, err, tt.overflows)
+		})
+	}
+}
+
+func TestMemoryBytes(t *testing.T) {
+	_, m64, err := runWords[int, IntWords](compare8, []int{9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, m32, err := runWords[int32, Int32Words](compare8, []int{9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m64.MemoryBytes() != 2*m32.MemoryBytes() {
+		t.Errorf("int words take %d bytes, int32 words %d", m64.MemoryBytes(), m32.MemoryBytes())
+	}
+}
+
+// benchmarkWords runs the day 5 diagnostic program on words of type W
+func benchmarkWords[W any, A Arithmetic[W]](b *testing.B) {
+	program, err := LoadProgram("testdata/diagnostic.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := runWords[W, A](program, []int{5}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWords(b *testing.B) {
+	b.Run("int", benchmarkWords[int, IntWords])
+	b.Run("int32", benchmarkWords[int32, Int32Words])
+	b.Run("int64", benchmarkWords[int64, Int64Words])
+	b.Run("big", benchmarkWords[*big.Int, BigWords])
+}
+
+func BenchmarkRun(b *testing.B) {
+	program, err := LoadProgram("testdata/diagnostic.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := NewComputer(program)
+		c.Send(5)
+		c.CloseInput()
+		if _, err := c.Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
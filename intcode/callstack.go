@@ -0,0 +1,100 @@
+package intcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Frame is a function call inferred by CallStack
+type Frame struct {
+	// Entry is the address the call jumped to, zero for the outermost frame
+	Entry int
+	// CallSite is the address of the jump that made the call, -1 for the outermost frame
+	CallSite int
+	// ReturnAddress is the address the call returns to, stored at the relative base of the caller
+	ReturnAddress int
+	// Base is the relative base at the call
+	Base int
+	// Size is how far the function moved the relative base above Base
+	Size int
+}
+
+// Name names the function by its entry address
+func (f Frame) Name() string {
+	if f.CallSite < 0 {
+		return "main"
+	}
+	return fmt.Sprintf("fn@%d", f.Entry)
+}
+
+// CallStack infers function calls from the way compiled programs use the relative base as a stack pointer. A caller
+// stores the return address at [rb] and jumps to the function, which moves the relative base up by its frame size.
+// To return it moves the relative base back down and jumps to [rb]. A taken jump that finds the address after
+// itself at [rb] is a call, an indirect jump to the return address of a frame returns from it and every frame above.
+type CallStack struct {
+	frames []Frame
+	ip     int
+}
+
+// NewCallStack creates a call stack with the outermost frame, add it to a computer with WithTracer
+func NewCallStack() *CallStack {
+	return &CallStack{frames: []Frame{{CallSite: -1, ReturnAddress: -1}}}
+}
+
+// Trace follows calls, returns and relative base adjustments
+func (s *CallStack) Trace(c Inspector, e Event) {
+	s.ip = e.NextIP
+	top := &s.frames[len(s.frames)-1]
+	switch e.Opcode {
+	case ADJ_RELATIVE_BASE:
+		top.Size = c.RelativeBase() - top.Base
+	case JMP_IF_TRUE, JMP_IF_FALSE:
+		if !e.Jumped {
+			return
+		}
+		if e.Instruction/1000%10 != IMMEDIATE_MODE {
+			for i := len(s.frames) - 1; i > 0; i-- {
+				if s.frames[i].ReturnAddress == e.NextIP {
+					s.frames = s.frames[:i]
+					return
+				}
+			}
+		}
+		ret := e.IP + 3
+		if stored, ok := c.Int(e.RelativeBase); ok && e.RelativeBase >= 0 && stored == ret {
+			s.frames = append(s.frames, Frame{Entry: e.NextIP, CallSite: e.IP, ReturnAddress: ret, Base: e.RelativeBase})
+		}
+	}
+}
+
+// Depth returns the number of frames
+func (s *CallStack) Depth() int {
+	return len(s.frames)
+}
+
+// Frames returns the frames, innermost first
+func (s *CallStack) Frames() []Frame {
+	frames := make([]Frame, len(s.frames))
+	for i, f := range s.frames {
+		frames[len(frames)-1-i] = f
+	}
+	return frames
+}
+
+// Backtrace lists the frames innermost first with the address each one is at: the instruction pointer for the
+// innermost frame and the call site of the frame above for the others
+func (s *CallStack) Backtrace() string {
+	var b strings.Builder
+	frames := s.Frames()
+	ip := s.ip
+	for i, f := range frames {
+		fmt.Fprintf(&b, "#%-2d %-6d in %-10s frame %d at rb %d\n", i, ip, f.Name(), f.Size, f.Base)
+		ip = f.CallSite
+	}
+	return b.String()
+}
+
+// IP returns the instruction pointer after the last traced instruction
+func (s *CallStack) IP() int {
+	return s.ip
+}
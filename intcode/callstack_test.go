@@ -0,0 +1,92 @@
+package intcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// nested has main call f, which calls g. Every function outputs a value, f once before and once after its call.
+const nested = `        arb  stack
+        add  r1, 0, [rb]
+        jt   1, f
+r1:     out  0
+        halt
+f:      arb  2
+        out  1
+        add  r2, 0, [rb]
+        jt   1, g
+r2:     out  3
+        arb  -2
+        jt   1, [rb]
+g:      arb  3
+        out  2
+        arb  -3
+        jt   1, [rb]
+stack:  data 0
+`
+
+// stackAtOutput records the frames of a call stack at every output
+type stackAtOutput struct {
+	stack  *CallStack
+	frames [][]Frame
+}
+
+func (s *stackAtOutput) Trace(c Inspector, e Event) {
+	if e.Opcode == OUTPUT {
+		s.frames = append(s.frames, s.stack.Frames())
+	}
+}
+
+func TestCallStack(t *testing.T) {
+	program, _, err := Assemble(strings.NewReader(nested), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, g, stack := 12, 30, 39
+
+	s := &stackAtOutput{stack: NewCallStack()}
+	c := NewComputer(program, WithTracer(s.stack), WithTracer(s))
+	if _, err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if out := c.TakeOutput(); !reflect.DeepEqual(out, []int{1, 2, 3, 0}) {
+		t.Fatalf("output %v, want [1 2 3 0]", out)
+	}
+
+	main := Frame{CallSite: -1, ReturnAddress: -1, Size: stack}
+	inF := Frame{Entry: f, CallSite: 6, ReturnAddress: 9, Base: stack, Size: 2}
+	inG := Frame{Entry: g, CallSite: 20, ReturnAddress: 23, Base: stack + 2, Size: 3}
+	want := [][]Frame{{inF, main}, {inG, inF, main}, {inF, main}, {main}}
+	if !reflect.DeepEqual(s.frames, want) {
+		t.Errorf("frames %v, want %v", s.frames, want)
+	}
+
+	if depth := s.stack.Depth(); depth != 1 {
+		t.Errorf("depth %d after the program, want 1", depth)
+	}
+	if names := []string{inG.Name(), main.Name()}; names[0] != "fn@30" || names[1] != "main" {
+		t.Errorf("names %v", names)
+	}
+}
+
+func TestBacktrace(t *testing.T) {
+	program, _, err := Assemble(strings.NewReader(strings.Replace(nested, "out  2", "data 42, 0", 1)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stack := NewCallStack()
+	c := NewComputer(program, WithTracer(stack))
+	if result, _ := c.Run(); result.Reason != HaltFault {
+		t.Fatalf("stopped with %v, want a fault", result.Reason)
+	}
+	lines := strings.Split(strings.TrimSpace(stack.Backtrace()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("backtrace\n%s", stack.Backtrace())
+	}
+	for i, want := range []string{"#0  32     in fn@30", "#1  20     in fn@12", "#2  6      in main"} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("backtrace line %q, want %q", lines[i], want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"os"
+)
+
+// asm assembles a source file into a text program
+func asm(args []string) error {
+	flags := flag.NewFlagSet("asm", flag.ExitOnError)
+	output := flags.String("o", "-", "output file, - for stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode asm [-o output] source.ica\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("asm needs exactly one source file")
+	}
+
+	data, err := readFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	program, _, err := intcode.Assemble(bytes.NewReader(data), flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%s: %v", flags.Arg(0), err)
+	}
+	var out bytes.Buffer
+	if err := writeProgram(&out, program); err != nil {
+		return err
+	}
+	return writeFile(*output, out.Bytes())
+}
+
+// disasm lists a program in the assembler syntax
+func disasm(args []string) error {
+	flags := flag.NewFlagSet("disasm", flag.ExitOnError)
+	output := flags.String("o", "-", "output file, - for stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode disasm [-o output] program\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("disasm needs exactly one program")
+	}
+
+	program, err := intcode.LoadProgram(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	listing, _ := intcode.Disassemble(program)
+	return writeFile(*output, []byte(listing))
+}
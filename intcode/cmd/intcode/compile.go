@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"os"
+)
+
+// compile translates a source file of the intcode language into a program
+func compile(args []string) error {
+	flags := flag.NewFlagSet("compile", flag.ExitOnError)
+	output := flags.String("o", "-", "output file, - for stdout")
+	asmOutput := flags.Bool("S", false, "write the generated assembler source instead of the program")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode compile [-o output] [-S] source\n\n"+
+			"Compiles a source file of the intcode language, see the intcode package documentation. Debug the\n"+
+			"source with intcode dap, which compiles .icl files itself.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("compile needs exactly one source file")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	program, _, asm, err := intcode.Compile(f, flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%s: %v", flags.Arg(0), err)
+	}
+	if *asmOutput {
+		return writeFile(*output, []byte(asm))
+	}
+	var out bytes.Buffer
+	if err := writeProgram(&out, program); err != nil {
+		return err
+	}
+	return writeFile(*output, out.Bytes())
+}
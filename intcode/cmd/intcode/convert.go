@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// convert turns text programs and JSON states into the binary format and back, depending on the input
+func convert(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	output := flags.String("o", "-", "output file, - for stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode convert [-o output] input\n\n"+
+			"Converts a text program to binary and a binary program to text. States are converted between\n"+
+			"binary and JSON. The input may be - for stdin and may be gzip compressed.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("convert needs exactly one input")
+	}
+
+	data, err := readFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if intcode.IsBinary(data) {
+		err = binaryToText(data, &out)
+	} else {
+		err = textToBinary(data, &out)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFile(*output, out.Bytes())
+}
+
+func binaryToText(data []byte, w io.Writer) error {
+	kind, err := intcode.BinaryKind(data)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case 'P':
+		program, err := intcode.DecodeProgram(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		return writeProgram(w, program)
+	case 'S':
+		state, err := intcode.DecodeState(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(state)
+	default:
+		return fmt.Errorf("unknown binary kind %q", kind)
+	}
+}
+
+func textToBinary(data []byte, w io.Writer) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var state intcode.State
+		if err := json.Unmarshal(trimmed, &state); err != nil {
+			return fmt.Errorf("parse state: %v", err)
+		}
+		return intcode.EncodeState(w, &state)
+	}
+
+	program, err := intcode.ReadProgram(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return intcode.EncodeProgram(w, program)
+}
+
+// writeProgram writes the program in the comma separated text format
+func writeProgram(w io.Writer, program []int) error {
+	values := make([]string, len(program))
+	for i, val := range program {
+		values[i] = strconv.Itoa(val)
+	}
+	_, err := fmt.Fprintln(w, strings.Join(values, ","))
+	return err
+}
+
+// readFile reads the whole file, or stdin for "-", and decompresses gzip data
+func readFile(path string) ([]byte, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return ioutil.ReadAll(zr)
+	}
+	return data, nil
+}
+
+// writeFile writes the data to the file, or stdout for "-"
+func writeFile(path string, data []byte) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"io/ioutil"
+	"os"
+)
+
+// cover runs a program once per input and reports which instructions and jump directions ran
+func cover(args []string) error {
+	flags := flag.NewFlagSet("cover", flag.ExitOnError)
+	output := flags.String("o", "-", "text report file, - for stdout")
+	htmlOutput := flags.String("html", "", "also write an HTML report to `file`")
+	data := flags.String("data", "", "coverage `file` that earlier runs are merged from and the result is saved to")
+	var inputs inputFlags
+	flags.Var(&inputs, "input", "comma separated `values` for a run, may be repeated")
+	maxSteps := flags.Int("max-steps", 10000000, "stop runs after `n` instructions, 0 for no limit")
+	specName := flags.String("spec", "day09", "stop runs that use features beyond the spec `level`: day02, day05, day09 or day11")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode cover [-o report] [-html file] [-data file] [-input values]... [-max-steps n] [-spec level] program\n\n"+
+			"Runs the program for every -input, or once without input, and reports the instructions that ran and\n"+
+			"the directions conditional jumps took over the disassembly.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("cover needs exactly one program")
+	}
+
+	spec, err := intcode.ParseSpec(*specName)
+	if err != nil {
+		return err
+	}
+	program, err := intcode.LoadProgram(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	cov := intcode.NewCoverage()
+	if *data != "" {
+		if saved, err := ioutil.ReadFile(*data); err == nil {
+			var earlier intcode.Coverage
+			if err := json.Unmarshal(saved, &earlier); err != nil {
+				return fmt.Errorf("read coverage %s: %v", *data, err)
+			}
+			cov.Merge(&earlier)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if len(inputs) == 0 {
+		inputs = inputFlags{nil}
+	}
+	for _, input := range inputs {
+		c := intcode.NewComputer(program, intcode.WithTracer(cov), intcode.WithLimits(intcode.Limits{MaxSteps: *maxSteps}),
+			intcode.WithSpec(spec))
+		c.Send(input...)
+		c.CloseInput()
+		if _, err := c.Run(); err != nil {
+			logSugar.Warnw("run stopped", "input", input, "error", err)
+		}
+	}
+
+	if *data != "" {
+		saved, err := json.Marshal(cov)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(*data, saved, 0644); err != nil {
+			return err
+		}
+	}
+	if *htmlOutput != "" {
+		var out bytes.Buffer
+		if err := cov.WriteHTML(&out, program); err != nil {
+			return err
+		}
+		if err := writeFile(*htmlOutput, out.Bytes()); err != nil {
+			return err
+		}
+	}
+	var out bytes.Buffer
+	if err := cov.WriteText(&out, program); err != nil {
+		return err
+	}
+	return writeFile(*output, out.Bytes())
+}
@@ -0,0 +1,916 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// debugChunk is how many instructions run between checks for a pause request
+	debugChunk = 10000
+	// disassemblyReference is the source reference of the generated listing of programs without assembler source
+	disassemblyReference = 1
+	// memoryPage is the number of memory cells in one entry of the memory view
+	memoryPage = 100
+)
+
+// variable references of the scopes, memory pages follow pageReference
+const (
+	registersReference = 1
+	memoryReference    = 2
+	outputsReference   = 3
+	pageReference      = 1000
+)
+
+// dapMode runs the debugger until the program is stepped, stopped or ends
+type dapMode int
+
+const (
+	modeContinue dapMode = iota
+	modeStepInstruction
+	modeStepLine
+	// modeStepOut runs until the current call frame returns
+	modeStepOut
+)
+
+// dap serves the Debug Adapter Protocol on stdio or a local socket
+func dap(args []string) error {
+	flags := flag.NewFlagSet("dap", flag.ExitOnError)
+	listen := flags.String("listen", "", "serve debug sessions on the local `address` instead of stdio")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode dap [-listen address]\n\n"+
+			"Speaks the Debug Adapter Protocol. The launch request takes \"program\", a text, binary, assembler (.ica) or\n"+
+			"intcode language (.icl) file, and optionally \"stopOnEntry\" and \"input\", a list of values queued before the program starts.\n"+
+			"Values typed in the debug console are sent to the program input, \"close\" closes it.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *listen == "" {
+		return newDebugger(os.Stdout).serve(os.Stdin)
+	}
+	l, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return err
+	}
+	log.Info("serving debug sessions", zap.String("addr", l.Addr().String()))
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := newDebugger(conn).serve(conn); err != nil {
+				log.Warn("debug session failed", zap.Error(err))
+			}
+		}()
+	}
+}
+
+// dapRequest is a request from the client
+type dapRequest struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type dapResponse struct {
+	Seq        int         `json:"seq"`
+	Type       string      `json:"type"`
+	RequestSeq int         `json:"request_seq"`
+	Success    bool        `json:"success"`
+	Command    string      `json:"command"`
+	Message    string      `json:"message,omitempty"`
+	Body       interface{} `json:"body,omitempty"`
+}
+
+type dapEvent struct {
+	Seq   int         `json:"seq"`
+	Type  string      `json:"type"`
+	Event string      `json:"event"`
+	Body  interface{} `json:"body,omitempty"`
+}
+
+type dapSource struct {
+	Name            string `json:"name,omitempty"`
+	Path            string `json:"path,omitempty"`
+	SourceReference int    `json:"sourceReference,omitempty"`
+}
+
+type dapBreakpoint struct {
+	ID                   int        `json:"id"`
+	Verified             bool       `json:"verified"`
+	Message              string     `json:"message,omitempty"`
+	Source               *dapSource `json:"source,omitempty"`
+	Line                 int        `json:"line,omitempty"`
+	InstructionReference string     `json:"instructionReference,omitempty"`
+}
+
+type dapVariable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	VariablesReference int    `json:"variablesReference"`
+	MemoryReference    string `json:"memoryReference,omitempty"`
+}
+
+// debugger is a single debug session
+type debugger struct {
+	wmu sync.Mutex
+	w   io.Writer
+	seq int
+
+	mu      sync.Mutex
+	program []int
+	c       *intcode.Computer
+	source  dapSource
+	listing string
+	smap    *intcode.SourceMap
+	// lineBreaks come from source breakpoints, addressBreaks from function and instruction breakpoints
+	lineBreaks    map[int]bool
+	addressBreaks map[int]bool
+	nextBreakID   int
+	stopOnEntry   bool
+	mode          dapMode
+	// outDepth is the call depth a step out returns below
+	outDepth int
+	pause    bool
+	// running is set while the program executes or waits for input
+	running    bool
+	waiting    bool
+	terminated bool
+	// faulted is set when the program faulted or hit a limit, it stays stopped for inspection until resumed
+	faulted bool
+	// stack infers the call frames of the program
+	stack *intcode.CallStack
+
+	resume chan struct{}
+	done   chan struct{}
+}
+
+func newDebugger(w io.Writer) *debugger {
+	return &debugger{
+		w:             w,
+		lineBreaks:    make(map[int]bool),
+		addressBreaks: make(map[int]bool),
+		resume:        make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+}
+
+// serve handles requests until the client disconnects
+func (d *debugger) serve(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	defer close(d.done)
+	for {
+		req, err := readDapMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		log.Debug("dap request", zap.String("command", req.Command), zap.Int("seq", req.Seq))
+
+		body, err := d.handle(req)
+		resp := dapResponse{Type: "response", RequestSeq: req.Seq, Command: req.Command, Success: err == nil, Body: body}
+		if err != nil {
+			resp.Message = err.Error()
+		}
+		d.send(&resp)
+		if req.Command == "initialize" && err == nil {
+			d.event("initialized", nil)
+		}
+		if req.Command == "configurationDone" && err == nil {
+			d.start()
+		}
+		if req.Command == "terminate" {
+			d.event("exited", map[string]int{"exitCode": 0})
+			d.event("terminated", nil)
+		}
+		if req.Command == "disconnect" {
+			return nil
+		}
+	}
+}
+
+func readDapMessage(r *bufio.Reader) (*dapRequest, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	var req dapRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// send writes a response or event with the next sequence number
+func (d *debugger) send(msg interface{}) {
+	d.wmu.Lock()
+	defer d.wmu.Unlock()
+	d.seq++
+	switch m := msg.(type) {
+	case *dapResponse:
+		m.Seq = d.seq
+	case *dapEvent:
+		m.Seq = d.seq
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Warn("failed to encode dap message", zap.Error(err))
+		return
+	}
+	if _, err := fmt.Fprintf(d.w, "Content-Length: %d\r\n\r\n%s", len(data), data); err != nil {
+		log.Warn("failed to write dap message", zap.Error(err))
+	}
+}
+
+func (d *debugger) event(name string, body interface{}) {
+	d.send(&dapEvent{Type: "event", Event: name, Body: body})
+}
+
+func (d *debugger) console(category, text string) {
+	d.event("output", map[string]string{"category": category, "output": text})
+}
+
+func (d *debugger) handle(req *dapRequest) (interface{}, error) {
+	switch req.Command {
+	case "initialize":
+		return map[string]bool{
+			"supportsConfigurationDoneRequest": true,
+			"supportsFunctionBreakpoints":      true,
+			"supportsInstructionBreakpoints":   true,
+			"supportsDisassembleRequest":       true,
+			"supportsSteppingGranularity":      true,
+			"supportsEvaluateForHovers":        true,
+			"supportsTerminateRequest":         true,
+		}, nil
+	case "launch":
+		return nil, d.launch(req.Arguments)
+	case "setBreakpoints":
+		return d.setBreakpoints(req.Arguments)
+	case "setFunctionBreakpoints":
+		return d.setAddressBreakpoints(req.Arguments, "name")
+	case "setInstructionBreakpoints":
+		return d.setAddressBreakpoints(req.Arguments, "instructionReference")
+	case "setExceptionBreakpoints", "configurationDone":
+		return nil, nil
+	case "threads":
+		return map[string]interface{}{"threads": []map[string]interface{}{{"id": 1, "name": "intcode"}}}, nil
+	case "stackTrace":
+		return d.stackTrace()
+	case "scopes":
+		return map[string]interface{}{"scopes": []map[string]interface{}{
+			{"name": "Registers", "variablesReference": registersReference, "expensive": false},
+			{"name": "Memory", "variablesReference": memoryReference, "expensive": true},
+			{"name": "Outputs", "variablesReference": outputsReference, "expensive": false},
+		}}, nil
+	case "variables":
+		return d.variables(req.Arguments)
+	case "source":
+		return d.sourceContent(req.Arguments)
+	case "continue":
+		return map[string]bool{"allThreadsContinued": true}, d.resumeWith(modeContinue)
+	case "next", "stepIn":
+		var args struct {
+			Granularity string `json:"granularity"`
+		}
+		_ = json.Unmarshal(req.Arguments, &args)
+		mode := modeStepLine
+		if args.Granularity == "instruction" {
+			mode = modeStepInstruction
+		}
+		return nil, d.resumeWith(mode)
+	case "stepOut":
+		return nil, d.resumeWith(modeStepOut)
+	case "pause":
+		return nil, d.requestPause()
+	case "evaluate":
+		return d.evaluate(req.Arguments)
+	case "disassemble":
+		return d.disassemble(req.Arguments)
+	case "terminate", "disconnect":
+		d.mu.Lock()
+		d.terminated = true
+		d.mu.Unlock()
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported request %q", req.Command)
+	}
+}
+
+func (d *debugger) launch(raw json.RawMessage) error {
+	var args struct {
+		Program     string `json:"program"`
+		StopOnEntry bool   `json:"stopOnEntry"`
+		Input       []int  `json:"input"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return err
+	}
+	if args.Program == "" {
+		return errors.New("launch needs a program")
+	}
+
+	var program []int
+	var smap *intcode.SourceMap
+	source := dapSource{Name: filepath.Base(args.Program)}
+	listing := ""
+	if ext := filepath.Ext(args.Program); ext == ".ica" || ext == ".asm" || ext == ".icl" {
+		path, err := filepath.Abs(args.Program)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if ext == ".icl" {
+			program, smap, _, err = intcode.Compile(f, path)
+		} else {
+			program, smap, err = intcode.Assemble(f, path)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		source.Path = path
+	} else {
+		var err error
+		if program, err = intcode.LoadProgram(args.Program); err != nil {
+			return err
+		}
+		listing, smap = intcode.Disassemble(program)
+		source.Name += " (disassembly)"
+		source.SourceReference = disassemblyReference
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.program = program
+	d.smap = smap
+	d.source = source
+	d.listing = listing
+	d.stopOnEntry = args.StopOnEntry
+	d.stack = intcode.NewCallStack()
+	d.c = intcode.NewComputer(program, intcode.WithInputPolicy(intcode.InputSuspend), intcode.WithTracer(d.stack))
+	d.c.Send(args.Input...)
+	log.Info("launched", zap.String("program", args.Program), zap.Int("length", len(program)))
+	return nil
+}
+
+func (d *debugger) setBreakpoints(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Source      dapSource `json:"source"`
+		Breakpoints []struct {
+			Line int `json:"line"`
+		} `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.smap == nil {
+		return nil, errors.New("no program launched")
+	}
+	ours := args.Source.SourceReference != 0 && args.Source.SourceReference == d.source.SourceReference
+	if d.source.Path != "" {
+		if path, err := filepath.Abs(args.Source.Path); err == nil && path == d.source.Path {
+			ours = true
+		}
+	}
+
+	d.lineBreaks = make(map[int]bool)
+	breakpoints := make([]dapBreakpoint, 0, len(args.Breakpoints))
+	lines := d.smap.Lines()
+	for _, bp := range args.Breakpoints {
+		d.nextBreakID++
+		result := dapBreakpoint{ID: d.nextBreakID, Line: bp.Line, Source: &d.source}
+		if !ours {
+			result.Message = "source is not part of the program"
+			breakpoints = append(breakpoints, result)
+			continue
+		}
+		// a line without code breaks on the next line that has some
+		for _, line := range lines {
+			if line >= bp.Line {
+				address, _ := d.smap.Address(line)
+				d.lineBreaks[address] = true
+				result.Verified = true
+				result.Line = line
+				result.InstructionReference = strconv.Itoa(address)
+				break
+			}
+		}
+		if !result.Verified {
+			result.Message = "no code at or after this line"
+		}
+		breakpoints = append(breakpoints, result)
+	}
+	return map[string]interface{}{"breakpoints": breakpoints}, nil
+}
+
+// setAddressBreakpoints handles function breakpoints, whose names are addresses, and instruction breakpoints. Both
+// replace the previous set of address breakpoints.
+func (d *debugger) setAddressBreakpoints(raw json.RawMessage, field string) (interface{}, error) {
+	var args struct {
+		Breakpoints []map[string]interface{} `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.addressBreaks = make(map[int]bool)
+	breakpoints := make([]dapBreakpoint, 0, len(args.Breakpoints))
+	for _, bp := range args.Breakpoints {
+		d.nextBreakID++
+		result := dapBreakpoint{ID: d.nextBreakID}
+		ref, _ := bp[field].(string)
+		address, err := strconv.ParseInt(strings.TrimSpace(ref), 0, 64)
+		if offset, ok := bp["offset"].(float64); ok {
+			address += int64(offset)
+		}
+		if err != nil || address < 0 {
+			result.Message = fmt.Sprintf("%q is not an address", ref)
+		} else {
+			d.addressBreaks[int(address)] = true
+			result.Verified = true
+			result.InstructionReference = strconv.Itoa(int(address))
+			if d.smap != nil {
+				if line, ok := d.smap.Line(int(address)); ok {
+					result.Line = line
+					result.Source = &d.source
+				}
+			}
+		}
+		breakpoints = append(breakpoints, result)
+	}
+	return map[string]interface{}{"breakpoints": breakpoints}, nil
+}
+
+// start begins execution once the client finished configuring
+func (d *debugger) start() {
+	d.mu.Lock()
+	if d.c == nil {
+		d.mu.Unlock()
+		return
+	}
+	stopOnEntry := d.stopOnEntry
+	d.running = !stopOnEntry
+	d.mu.Unlock()
+
+	go d.loop()
+	if stopOnEntry {
+		d.event("stopped", map[string]interface{}{"reason": "entry", "threadId": 1, "allThreadsStopped": true})
+	} else {
+		d.resume <- struct{}{}
+	}
+}
+
+func (d *debugger) resumeWith(mode dapMode) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.c == nil || d.terminated {
+		return errors.New("the program is not running")
+	}
+	if d.running && !d.waiting {
+		return errors.New("the program is already running")
+	}
+	if d.faulted {
+		// the program cannot continue after a fault, resuming ends the session
+		go d.finish(1)
+		return nil
+	}
+	d.mode = mode
+	// a step out stops once the current frame returns, out of the outermost frame it runs to the end
+	d.outDepth = d.stack.Depth()
+	d.running = true
+	d.signal()
+	return nil
+}
+
+func (d *debugger) requestPause() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return nil
+	}
+	if d.waiting {
+		// nothing executes while the program waits for input, so it stops right away
+		d.running = false
+		d.waiting = false
+		go d.event("stopped", map[string]interface{}{"reason": "pause", "threadId": 1, "allThreadsStopped": true})
+		return nil
+	}
+	d.pause = true
+	return nil
+}
+
+// signal wakes the execution loop, the caller holds the lock
+func (d *debugger) signal() {
+	select {
+	case d.resume <- struct{}{}:
+	default:
+	}
+}
+
+// loop executes the program whenever it is resumed
+func (d *debugger) loop() {
+	for {
+		select {
+		case <-d.resume:
+		case <-d.done:
+			return
+		}
+
+		for {
+			d.mu.Lock()
+			if !d.running || d.terminated {
+				d.mu.Unlock()
+				break
+			}
+			reason, stopped := d.execute()
+			outputs := d.c.TakeOutput()
+			terminated := d.terminated
+			d.mu.Unlock()
+			if terminated {
+				return
+			}
+
+			for _, val := range outputs {
+				d.console("stdout", fmt.Sprintf("%d\n", val))
+			}
+			if !stopped {
+				continue
+			}
+			switch reason {
+			case "waiting":
+				d.console("console", "waiting for input, enter values in the debug console\n")
+			case "exited":
+				d.finish(0)
+				return
+			case "exception":
+				d.event("stopped", map[string]interface{}{"reason": "exception", "description": "program fault", "threadId": 1, "allThreadsStopped": true})
+			default:
+				d.event("stopped", map[string]interface{}{"reason": reason, "threadId": 1, "allThreadsStopped": true})
+			}
+			break
+		}
+	}
+}
+
+// execute runs a chunk of instructions, the caller holds the lock. It reports why execution stopped.
+func (d *debugger) execute() (reason string, stopped bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.console("stderr", fmt.Sprintf("program fault: %v\n%s", r, d.stack.Backtrace()))
+			d.running, d.faulted = false, true
+			reason, stopped = "exception", true
+		}
+	}()
+
+	startLine, _ := d.smap.Line(d.c.IP())
+	// the instruction the program stopped on runs before breakpoints are checked again
+	first := true
+	for i := 0; i < debugChunk; i++ {
+		if d.pause || d.terminated {
+			d.pause = false
+			d.running = false
+			return "pause", true
+		}
+		ip := d.c.IP()
+		if !first && (d.lineBreaks[ip] || d.addressBreaks[ip]) {
+			d.running = false
+			return "breakpoint", true
+		}
+		first = false
+
+		if d.c.E() {
+			if d.c.Waiting() {
+				d.waiting = true
+				return "waiting", true
+			}
+			if err := d.c.Err(); err != nil {
+				d.console("stderr", fmt.Sprintf("%v\n%s", err, d.stack.Backtrace()))
+				d.running, d.faulted = false, true
+				return "exception", true
+			}
+			if d.c.InputClosed() {
+				d.console("console", "the program wanted input after the input was closed\n")
+			}
+			return "exited", true
+		}
+		d.waiting = false
+
+		switch d.mode {
+		case modeStepInstruction:
+			d.running = false
+			return "step", true
+		case modeStepLine:
+			if line, ok := d.smap.Line(d.c.IP()); ok && line != startLine {
+				d.running = false
+				return "step", true
+			}
+		case modeStepOut:
+			if d.stack.Depth() < d.outDepth {
+				d.running = false
+				return "step", true
+			}
+		}
+	}
+	return "", false
+}
+
+func (d *debugger) finish(exitCode int) {
+	d.mu.Lock()
+	d.running = false
+	d.terminated = true
+	d.mu.Unlock()
+	d.event("exited", map[string]int{"exitCode": exitCode})
+	d.event("terminated", nil)
+}
+
+// stackTrace lists the call frames inferred from the relative base, innermost first
+func (d *debugger) stackTrace() (interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.c == nil {
+		return nil, errors.New("no program launched")
+	}
+	state := d.c.Snapshot()
+	var frames []interface{}
+	ip := state.IP
+	for i, f := range d.stack.Frames() {
+		frame := map[string]interface{}{
+			"id":                          i + 1,
+			"name":                        fmt.Sprintf("%s (frame %d): %s", f.Name(), f.Size, instructionText(state.Memory, ip)),
+			"column":                      1,
+			"instructionPointerReference": strconv.Itoa(ip),
+		}
+		if line, ok := d.smap.Line(ip); ok {
+			frame["line"] = line
+			frame["source"] = d.source
+		} else {
+			frame["line"] = 0
+		}
+		frames = append(frames, frame)
+		ip = f.CallSite
+	}
+	return map[string]interface{}{"stackFrames": frames, "totalFrames": len(frames)}, nil
+}
+
+// instructionText decodes the instruction at the address, or shows the cell as data
+func instructionText(memory []int, address int) string {
+	inst, err := intcode.Decode(memory, address)
+	if err == nil {
+		return inst.String()
+	}
+	if address < 0 || address >= len(memory) {
+		return "outside of memory"
+	}
+	return fmt.Sprintf("data %d", memory[address])
+}
+
+func (d *debugger) variables(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		VariablesReference int `json:"variablesReference"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.c == nil {
+		return nil, errors.New("no program launched")
+	}
+	state := d.c.Snapshot()
+
+	var vars []dapVariable
+	switch ref := args.VariablesReference; {
+	case ref == registersReference:
+		vars = []dapVariable{
+			{Name: "ip", Value: strconv.Itoa(state.IP), MemoryReference: strconv.Itoa(state.IP)},
+			{Name: "rb", Value: strconv.Itoa(state.RelativeBase), MemoryReference: strconv.Itoa(state.RelativeBase)},
+			{Name: "steps", Value: strconv.Itoa(d.c.Steps())},
+			{Name: "instruction", Value: instructionText(state.Memory, state.IP)},
+			{Name: "input", Value: fmt.Sprint(state.Input)},
+		}
+	case ref == memoryReference:
+		for start := 0; start < len(state.Memory); start += memoryPage {
+			end := start + memoryPage - 1
+			if end >= len(state.Memory) {
+				end = len(state.Memory) - 1
+			}
+			vars = append(vars, dapVariable{
+				Name:               fmt.Sprintf("[%d..%d]", start, end),
+				Value:              fmt.Sprint(state.Memory[start : end+1]),
+				VariablesReference: pageReference + start/memoryPage,
+				MemoryReference:    strconv.Itoa(start),
+			})
+		}
+	case ref == outputsReference:
+		// outputs are shown in the console as they happen, the view keeps the queued ones
+		for i, val := range state.Output {
+			vars = append(vars, dapVariable{Name: strconv.Itoa(i), Value: strconv.Itoa(val)})
+		}
+	case ref >= pageReference:
+		start := (ref - pageReference) * memoryPage
+		for address := start; address < start+memoryPage && address < len(state.Memory); address++ {
+			vars = append(vars, dapVariable{Name: fmt.Sprintf("[%d]", address), Value: strconv.Itoa(state.Memory[address])})
+		}
+	default:
+		return nil, fmt.Errorf("unknown variables reference %d", ref)
+	}
+	if vars == nil {
+		vars = []dapVariable{}
+	}
+	return map[string]interface{}{"variables": vars}, nil
+}
+
+func (d *debugger) sourceContent(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		SourceReference int `json:"sourceReference"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if args.SourceReference != disassemblyReference || d.listing == "" {
+		return nil, fmt.Errorf("unknown source reference %d", args.SourceReference)
+	}
+	return map[string]string{"content": d.listing, "mimeType": "text/x-intcode"}, nil
+}
+
+var (
+	inputExpr  = regexp.MustCompile(`^-?\d+(\s*,\s*-?\d+)*$`)
+	memoryExpr = regexp.MustCompile(`^\[\s*(\d+)\s*(?:\.\.\s*(\d+)\s*)?\]$`)
+)
+
+// evaluate reads registers and memory, [a] or [a..b]. In the console, numbers are sent to the program input.
+func (d *debugger) evaluate(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Expression string `json:"expression"`
+		Context    string `json:"context"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	expr := strings.TrimSpace(args.Expression)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.c == nil {
+		return nil, errors.New("no program launched")
+	}
+	result := func(s string) (interface{}, error) {
+		return map[string]interface{}{"result": s, "variablesReference": 0}, nil
+	}
+
+	switch {
+	case args.Context == "repl" && inputExpr.MatchString(expr):
+		var values []int
+		for _, field := range strings.Split(expr, ",") {
+			val, _ := strconv.Atoi(strings.TrimSpace(field))
+			values = append(values, val)
+		}
+		d.c.Send(values...)
+		if d.waiting && d.running {
+			d.signal()
+		}
+		return result(fmt.Sprintf("sent %v", values))
+	case args.Context == "repl" && expr == "close":
+		d.c.CloseInput()
+		if d.waiting && d.running {
+			d.signal()
+		}
+		return result("input closed")
+	case expr == "ip":
+		return result(strconv.Itoa(d.c.IP()))
+	case expr == "rb":
+		return result(strconv.Itoa(d.c.Snapshot().RelativeBase))
+	}
+
+	if m := memoryExpr.FindStringSubmatch(expr); m != nil {
+		memory := d.c.Snapshot().Memory
+		start, _ := strconv.Atoi(m[1])
+		end := start
+		if m[2] != "" {
+			end, _ = strconv.Atoi(m[2])
+		}
+		if end < start || end >= len(memory) {
+			return nil, fmt.Errorf("%s is outside of memory", expr)
+		}
+		if start == end {
+			return result(strconv.Itoa(memory[start]))
+		}
+		return result(fmt.Sprint(memory[start : end+1]))
+	}
+	return nil, fmt.Errorf("cannot evaluate %q, use ip, rb, [address] or [from..to]", expr)
+}
+
+// disassemble decodes instructions around a memory reference. The listing is a linear sweep from address zero, so
+// instruction offsets are counted in that listing.
+func (d *debugger) disassemble(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		MemoryReference   string `json:"memoryReference"`
+		Offset            int    `json:"offset"`
+		InstructionOffset int    `json:"instructionOffset"`
+		InstructionCount  int    `json:"instructionCount"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	ref, err := strconv.ParseInt(args.MemoryReference, 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory reference %q", args.MemoryReference)
+	}
+
+	d.mu.Lock()
+	if d.c == nil {
+		d.mu.Unlock()
+		return nil, errors.New("no program launched")
+	}
+	memory := d.c.Snapshot().Memory
+	length := len(d.program)
+	d.mu.Unlock()
+	if length > len(memory) {
+		length = len(memory)
+	}
+
+	var addresses []int
+	for address := 0; address < length; {
+		addresses = append(addresses, address)
+		if inst, err := intcode.Decode(memory, address); err == nil {
+			address += inst.Len()
+		} else {
+			address++
+		}
+	}
+	index := len(addresses)
+	for i, address := range addresses {
+		if address >= int(ref)+args.Offset {
+			index = i
+			break
+		}
+	}
+
+	instructions := make([]map[string]interface{}, 0, args.InstructionCount)
+	for i := index + args.InstructionOffset; len(instructions) < args.InstructionCount; i++ {
+		if i < 0 || i >= len(addresses) {
+			instructions = append(instructions, map[string]interface{}{
+				"address":          "-1",
+				"instruction":      "",
+				"presentationHint": "invalid",
+			})
+			continue
+		}
+		address := addresses[i]
+		entry := map[string]interface{}{
+			"address":          strconv.Itoa(address),
+			"instruction":      instructionText(memory, address),
+			"instructionBytes": strconv.Itoa(memory[address]),
+		}
+		if line, ok := d.smap.Line(address); ok {
+			entry["line"] = line
+			entry["location"] = d.source
+		}
+		instructions = append(instructions, entry)
+	}
+	return map[string]interface{}{"instructions": instructions}, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// calls has main call a function that outputs 1 and returns, then output 11
+const calls = `        arb  stack
+        add  ret, 0, [rb]
+        jt   1, fn
+ret:    out  [result]
+        halt
+fn:     arb  2
+        add  5, 6, [result]
+        out  1
+        arb  -2
+        jt   1, [rb]
+result: data 0
+stack:  data 0
+`
+
+// dapClient talks to a debugger over pipes
+type dapClient struct {
+	t        *testing.T
+	w        io.Writer
+	seq      int
+	messages chan map[string]interface{}
+}
+
+func newDapClient(t *testing.T) *dapClient {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	d := newDebugger(outW)
+	go d.serve(inR)
+	t.Cleanup(func() {
+		inW.Close()
+		outW.Close()
+	})
+
+	c := &dapClient{t: t, w: inW, messages: make(chan map[string]interface{}, 100)}
+	go func() {
+		r := bufio.NewReader(outR)
+		for {
+			header, err := textproto.NewReader(r).ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+			length, _ := strconv.Atoi(header.Get("Content-Length"))
+			data := make([]byte, length)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return
+			}
+			var msg map[string]interface{}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+	return c
+}
+
+// call sends a request and waits for its response, which must succeed
+func (c *dapClient) call(command string, args interface{}) map[string]interface{} {
+	c.t.Helper()
+	c.seq++
+	data, err := json.Marshal(map[string]interface{}{"seq": c.seq, "type": "request", "command": command,
+		"arguments": args})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(data), data); err != nil {
+		c.t.Fatal(err)
+	}
+	msg := c.wait("response", command)
+	if msg["success"] != true {
+		c.t.Fatalf("%s failed: %v", command, msg["message"])
+	}
+	body, _ := msg["body"].(map[string]interface{})
+	return body
+}
+
+// wait skips messages until a response to the command or an event with the name
+func (c *dapClient) wait(kind, name string) map[string]interface{} {
+	c.t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-c.messages:
+			if msg["type"] == kind && (msg["command"] == name || msg["event"] == name) {
+				return msg
+			}
+		case <-timeout:
+			c.t.Fatalf("no %s %s", kind, name)
+		}
+	}
+}
+
+// stopped waits for the program to stop and returns the reason and the line and depth of the stack
+func (c *dapClient) stopped() (reason string, line, depth int) {
+	c.t.Helper()
+	body := c.wait("event", "stopped")["body"].(map[string]interface{})
+	frames := c.call("stackTrace", map[string]int{"threadId": 1})["stackFrames"].([]interface{})
+	top := frames[0].(map[string]interface{})
+	return body["reason"].(string), int(top["line"].(float64)), len(frames)
+}
+
+func TestDapStepOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.ica")
+	if err := ioutil.WriteFile(path, []byte(calls), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newDapClient(t)
+	c.call("initialize", map[string]string{"adapterID": "intcode"})
+	c.call("launch", map[string]interface{}{"program": path})
+	c.call("setBreakpoints", map[string]interface{}{"source": map[string]string{"path": path},
+		"breakpoints": []map[string]int{{"line": 8}}})
+	c.call("configurationDone", nil)
+
+	if reason, line, depth := c.stopped(); reason != "breakpoint" || line != 8 || depth != 2 {
+		t.Fatalf("stopped for %s on line %d at depth %d, want a breakpoint on line 8 at depth 2", reason, line, depth)
+	}
+	c.call("stepOut", map[string]int{"threadId": 1})
+	if reason, line, depth := c.stopped(); reason != "step" || line != 4 || depth != 1 {
+		t.Fatalf("stopped for %s on line %d at depth %d, want a step to line 4 at depth 1", reason, line, depth)
+	}
+	// out of the outermost frame the program runs to the end
+	c.call("stepOut", map[string]int{"threadId": 1})
+	c.wait("event", "terminated")
+}
+
+func TestInstructionText(t *testing.T) {
+	memory := []int{1002, 4, 3, 4, 33, 42}
+	tests := []struct {
+		address int
+		want    string
+	}{
+		{0, "mul  [4], 3, [4]"},
+		{5, "data 42"},
+		{6, "outside of memory"},
+		{-1, "outside of memory"},
+	}
+	for _, tt := range tests {
+		if got := instructionText(memory, tt.address); got != tt.want {
+			t.Errorf("address %d is %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
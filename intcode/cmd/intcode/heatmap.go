@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"os"
+)
+
+// heatmap runs a program once per input and draws its memory accesses as a grid
+func heatmap(args []string) error {
+	flags := flag.NewFlagSet("heatmap", flag.ExitOnError)
+	output := flags.String("o", "-", "terminal view file, - for stdout")
+	pngOutput := flags.String("png", "", "write a PNG image to `file` instead of the terminal view")
+	scale := flags.Int("scale", 8, "pixels per cell of the PNG image")
+	width := flags.Int("width", 32, "`cells` per row")
+	accessName := flags.String("access", "all", "color cells by `access`: reads, writes, executes or all in one color")
+	var inputs inputFlags
+	flags.Var(&inputs, "input", "comma separated `values` for a run, may be repeated")
+	maxSteps := flags.Int("max-steps", 10000000, "stop runs after `n` instructions, 0 for no limit")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode heatmap [-o output] [-png file] [-scale n] [-width cells] [-access access] [-input values]... [-max-steps n] program\n\n"+
+			"Runs the program for every -input, or once without input, and colors every memory cell by how often it\n"+
+			"was read, written or executed. With -access all writes are red, executions green and reads blue, so\n"+
+			"self-modified code is yellow and the stack magenta.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("heatmap needs exactly one program")
+	}
+
+	h := intcode.Heatmap{Width: *width, Combined: *accessName == "all"}
+	if !h.Combined {
+		access, err := intcode.ParseAccess(*accessName)
+		if err != nil {
+			return err
+		}
+		h.Access = access
+	}
+	program, err := intcode.LoadProgram(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	h.Profile = intcode.NewProfile()
+	if len(inputs) == 0 {
+		inputs = inputFlags{nil}
+	}
+	for _, input := range inputs {
+		c := intcode.NewComputer(program, intcode.WithTracer(h.Profile), intcode.WithLimits(intcode.Limits{MaxSteps: *maxSteps}))
+		c.Send(input...)
+		c.CloseInput()
+		if _, err := c.Run(); err != nil {
+			logSugar.Warnw("run stopped", "input", input, "error", err)
+		}
+	}
+
+	if h.Profile.Size() == 0 {
+		return fmt.Errorf("%s: %w", flags.Arg(0), intcode.ErrEmptyProfile)
+	}
+
+	fmt.Fprintln(os.Stderr, h.Legend())
+	var out bytes.Buffer
+	if *pngOutput != "" {
+		if err := h.WritePNG(&out, *scale); err != nil {
+			return err
+		}
+		return writeFile(*pngOutput, out.Bytes())
+	}
+	if err := h.WriteANSI(&out); err != nil {
+		return err
+	}
+	return writeFile(*output, out.Bytes())
+}
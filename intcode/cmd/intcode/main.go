@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"sort"
+)
+
+var (
+	//log, _ = zap.NewDevelopment()
+	log, _   = zap.NewProduction()
+	logSugar = log.Sugar()
+)
+
+// command is a subcommand of the intcode tool. It receives the arguments after the command name.
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"asm":       {"assemble a source file into a program", asm},
+	"compile":   {"compile a source file of the intcode language into a program", compile},
+	"convert":   {"convert programs and states between the text and binary formats", convert},
+	"cover":     {"report the instructions and jump directions runs of a program exercised", cover},
+	"dap":       {"debug programs from an editor over the Debug Adapter Protocol", dap},
+	"heatmap":   {"draw how often every memory cell was read, written and executed", heatmap},
+	"disasm":    {"list a program in the assembler syntax", disasm},
+	"optimize":  {"fold constants and remove redundant jumps in a program", optimize},
+	"serve":     {"serve a local HTTP/JSON API to run programs in sessions", serve},
+	"sweep":     {"run a program for every combination of patched memory values", sweep},
+	"taint":     {"report which inputs every output of a program depends on", taint},
+	"trace":     {"print the instructions and calls a program executes", trace},
+	"tracediff": {"find where two JSONL traces of runs diverge", tracediff},
+	"transpile": {"compile a program to Go source", transpile},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		log.Fatal("command failed", zap.String("command", os.Args[1]), zap.Error(err))
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: intcode <command> [arguments]\n\ncommands:\n")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// inputFlags collects repeated -input flags, each one a comma separated list of values for one run
+type inputFlags [][]int
+
+func (f *inputFlags) String() string {
+	var parts []string
+	for _, input := range *f {
+		parts = append(parts, fmt.Sprint(input))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (f *inputFlags) Set(value string) error {
+	var input []int
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		val, err := strconv.Atoi(field)
+		if err != nil {
+			return err
+		}
+		input = append(input, val)
+	}
+	*f = append(*f, input)
+	return nil
+}
+
+// optimize rewrites a program with the peephole optimizer and checks it against the original
+func optimize(args []string) error {
+	flags := flag.NewFlagSet("optimize", flag.ExitOnError)
+	output := flags.String("o", "-", "output file, - for stdout")
+	var inputs inputFlags
+	flags.Var(&inputs, "input", "comma separated `values` for a verification run, may be repeated")
+	maxSteps := flags.Int("max-steps", 10000000, "stop verification runs after `n` instructions, 0 for no limit")
+	noVerify := flags.Bool("no-verify", false, "skip the verification runs")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode optimize [-o output] [-input values]... [-max-steps n] [-no-verify] program\n\n"+
+			"Folds constants, simplifies trivial arithmetic and removes jumps to the next instruction. The result\n"+
+			"runs next to the original for every -input, or once without input, and must behave the same.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("optimize needs exactly one program")
+	}
+
+	program, err := intcode.LoadProgram(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	optimized, stats := intcode.Optimize(program)
+	fmt.Fprintf(os.Stderr, "propagated %d, folded %d, simplified %d, jumps removed %d\n",
+		stats.Propagated, stats.Folded, stats.Simplified, stats.JumpsRemoved)
+
+	if !*noVerify {
+		if len(inputs) == 0 {
+			inputs = inputFlags{nil}
+		}
+		if err := intcode.VerifyEquivalent(program, optimized, inputs, intcode.Limits{MaxSteps: *maxSteps}); err != nil {
+			return fmt.Errorf("optimized program differs: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "verified with %d inputs\n", len(inputs))
+	}
+
+	var out strings.Builder
+	if err := writeProgram(&out, optimized); err != nil {
+		return err
+	}
+	return writeFile(*output, []byte(out.String()))
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"go.uber.org/zap"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// sessionChunk is how many instructions a session executes before other requests get the computer
+const sessionChunk = 10000
+
+// defaultMaxAddress keeps client programs from making the server allocate memory without bound, eight megabytes of
+// cells per session
+const defaultMaxAddress = 1 << 20
+
+// serve starts the HTTP service
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8019", "listen `address`")
+	maxSteps := flags.Int("max-steps", 0, "kill sessions after `n` instructions, no limit when zero")
+	maxAddress := flags.Int("max-address", defaultMaxAddress, "kill sessions that access memory above `address`")
+	maxOutputs := flags.Int("max-outputs", 0, "kill sessions that output more than `n` values, no limit when zero")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode serve [-addr address] [limits]\n\n"+
+			"Serves a JSON API to run programs:\n\n"+
+			"  POST   /programs                 upload a text or binary program\n"+
+			"  GET    /programs/{id}            the uploaded program\n"+
+			"  POST   /sessions                 start {\"program\": id} or {\"code\": [...]} or {\"state\": {...}}\n"+
+			"  GET    /sessions                 every session\n"+
+			"  GET    /sessions/{id}            the session status\n"+
+			"  POST   /sessions/{id}/input      send {\"values\": [...], \"close\": bool}\n"+
+			"  GET    /sessions/{id}/output     stream the outputs as server-sent events, from ?from=n\n"+
+			"  GET    /sessions/{id}/snapshot   the computer state as JSON, or binary for Accept: application/octet-stream\n"+
+			"  POST   /sessions/{id}/halt       stop the session\n"+
+			"  DELETE /sessions/{id}            stop and forget the session\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	srv := newServer(intcode.Limits{MaxSteps: *maxSteps, MaxAddress: *maxAddress, MaxOutputs: *maxOutputs})
+	log.Info("serving", zap.String("addr", *addr))
+	return http.ListenAndServe(*addr, srv)
+}
+
+// server holds the uploaded programs and the running sessions
+type server struct {
+	limits intcode.Limits
+
+	mu          sync.Mutex
+	programs    map[string][]int
+	sessions    map[string]*session
+	nextProgram int
+	nextSession int
+}
+
+// newServer creates a server whose sessions run with the limits. Sessions without a memory limit get
+// defaultMaxAddress.
+func newServer(limits intcode.Limits) *server {
+	if limits.MaxAddress <= 0 {
+		limits.MaxAddress = defaultMaxAddress
+	}
+	return &server{limits: limits, programs: make(map[string][]int), sessions: make(map[string]*session)}
+}
+
+// session is a computer running on its own goroutine
+type session struct {
+	id     string
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	c       *intcode.Computer
+	outputs []int
+	state   string
+	err     error
+	// changed is closed and replaced whenever outputs or state change
+	changed chan struct{}
+	// wake is signalled when a waiting computer got input
+	wake chan struct{}
+	// done is closed when the run goroutine returned
+	done chan struct{}
+}
+
+// sessionStatus is the JSON view of a session
+type sessionStatus struct {
+	ID      string `json:"id"`
+	State   string `json:"state"`
+	IP      int    `json:"ip"`
+	Steps   int    `json:"steps"`
+	Outputs int    `json:"outputs"`
+	Error   string `json:"error,omitempty"`
+}
+
+// httpError is an error with a status code for the client
+type httpError struct {
+	code int
+	msg  string
+}
+
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+func errorf(code int, format string, args ...interface{}) error {
+	return &httpError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+func (srv *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	log.Debug("request", zap.String("method", r.Method), zap.String("path", r.URL.Path))
+
+	var err error
+	switch {
+	case len(parts) == 1 && parts[0] == "programs" && r.Method == http.MethodPost:
+		err = srv.uploadProgram(w, r)
+	case len(parts) == 2 && parts[0] == "programs" && r.Method == http.MethodGet:
+		err = srv.getProgram(w, parts[1])
+	case len(parts) == 1 && parts[0] == "sessions" && r.Method == http.MethodPost:
+		err = srv.createSession(w, r)
+	case len(parts) == 1 && parts[0] == "sessions" && r.Method == http.MethodGet:
+		err = srv.listSessions(w)
+	case len(parts) >= 2 && parts[0] == "sessions":
+		err = srv.sessionRequest(w, r, parts[1], parts[2:])
+	default:
+		err = errorf(http.StatusNotFound, "no route for %s %s", r.Method, r.URL.Path)
+	}
+
+	if err != nil {
+		code := http.StatusInternalServerError
+		var he *httpError
+		if errors.As(err, &he) {
+			code = he.code
+		}
+		writeJSON(w, code, map[string]string{"error": err.Error()})
+	}
+}
+
+func (srv *server) sessionRequest(w http.ResponseWriter, r *http.Request, id string, rest []string) error {
+	srv.mu.Lock()
+	s, ok := srv.sessions[id]
+	srv.mu.Unlock()
+	if !ok {
+		return errorf(http.StatusNotFound, "no session %q", id)
+	}
+
+	action := ""
+	if len(rest) == 1 {
+		action = rest[0]
+	} else if len(rest) > 1 {
+		return errorf(http.StatusNotFound, "no route for %s %s", r.Method, r.URL.Path)
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.status())
+	case action == "" && r.Method == http.MethodDelete:
+		s.cancel()
+		srv.mu.Lock()
+		delete(srv.sessions, id)
+		srv.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case action == "input" && r.Method == http.MethodPost:
+		return s.input(w, r)
+	case action == "output" && r.Method == http.MethodGet:
+		return s.streamOutput(w, r)
+	case action == "snapshot" && r.Method == http.MethodGet:
+		return s.snapshot(w, r)
+	case action == "halt" && r.Method == http.MethodPost:
+		s.cancel()
+		// respond with the state the session stopped in
+		select {
+		case <-s.done:
+		case <-r.Context().Done():
+			return r.Context().Err()
+		}
+		writeJSON(w, http.StatusOK, s.status())
+	default:
+		return errorf(http.StatusNotFound, "no route for %s %s", r.Method, r.URL.Path)
+	}
+	return nil
+}
+
+func (srv *server) uploadProgram(w http.ResponseWriter, r *http.Request) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	program, err := intcode.ReadProgram(bytes.NewReader(body))
+	if err != nil {
+		return errorf(http.StatusBadRequest, "%v", err)
+	}
+
+	srv.mu.Lock()
+	srv.nextProgram++
+	id := "p" + strconv.Itoa(srv.nextProgram)
+	srv.programs[id] = program
+	srv.mu.Unlock()
+
+	log.Info("program uploaded", zap.String("program", id), zap.Int("length", len(program)))
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"id": id, "length": len(program)})
+	return nil
+}
+
+func (srv *server) getProgram(w http.ResponseWriter, id string) error {
+	srv.mu.Lock()
+	program, ok := srv.programs[id]
+	srv.mu.Unlock()
+	if !ok {
+		return errorf(http.StatusNotFound, "no program %q", id)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"id": id, "code": program})
+	return nil
+}
+
+func (srv *server) createSession(w http.ResponseWriter, r *http.Request) error {
+	var req struct {
+		Program string         `json:"program"`
+		Code    []int          `json:"code"`
+		State   *intcode.State `json:"state"`
+		Inputs  []int          `json:"inputs"`
+		Close   bool           `json:"close"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errorf(http.StatusBadRequest, "decode request: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	limits := srv.limits
+	limits.Context = ctx
+	opts := []intcode.Option{intcode.WithLimits(limits), intcode.WithInputPolicy(intcode.InputSuspend)}
+
+	var c *intcode.Computer
+	switch {
+	case req.State != nil:
+		c = intcode.Restore(req.State, opts...)
+	case req.Code != nil:
+		c = intcode.NewComputer(req.Code, opts...)
+	case req.Program != "":
+		srv.mu.Lock()
+		program, ok := srv.programs[req.Program]
+		srv.mu.Unlock()
+		if !ok {
+			cancel()
+			return errorf(http.StatusNotFound, "no program %q", req.Program)
+		}
+		c = intcode.NewComputer(program, opts...)
+	default:
+		cancel()
+		return errorf(http.StatusBadRequest, "a session needs a program, code or state")
+	}
+	c.Send(req.Inputs...)
+	if req.Close {
+		c.CloseInput()
+	}
+
+	srv.mu.Lock()
+	srv.nextSession++
+	s := &session{
+		id:      "s" + strconv.Itoa(srv.nextSession),
+		cancel:  cancel,
+		c:       c,
+		state:   "running",
+		changed: make(chan struct{}),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	srv.sessions[s.id] = s
+	srv.mu.Unlock()
+
+	log.Info("session started", zap.String("session", s.id))
+	go s.run(ctx)
+	writeJSON(w, http.StatusCreated, s.status())
+	return nil
+}
+
+func (srv *server) listSessions(w http.ResponseWriter) error {
+	srv.mu.Lock()
+	statuses := make([]sessionStatus, 0, len(srv.sessions))
+	for _, s := range srv.sessions {
+		statuses = append(statuses, s.status())
+	}
+	srv.mu.Unlock()
+	sort.Slice(statuses, func(i, j int) bool {
+		a, _ := strconv.Atoi(statuses[i].ID[1:])
+		b, _ := strconv.Atoi(statuses[j].ID[1:])
+		return a < b
+	})
+	writeJSON(w, http.StatusOK, statuses)
+	return nil
+}
+
+// run executes the computer in chunks and parks it while it waits for input
+func (s *session) run(ctx context.Context) {
+	defer close(s.done)
+	for {
+		s.mu.Lock()
+		stop, fault := s.execute()
+		s.outputs = append(s.outputs, s.c.TakeOutput()...)
+		switch {
+		case fault != nil:
+			s.err = fault
+			s.state = "faulted"
+		case !stop:
+			s.state = "running"
+		case s.c.Err() != nil:
+			s.err = s.c.Err()
+			s.state = "killed"
+			if errors.Is(s.err, context.Canceled) {
+				s.state = "stopped"
+			}
+		case s.c.Waiting():
+			s.state = "waiting"
+		case s.c.InputClosed():
+			s.state = "input closed"
+		default:
+			s.state = "halted"
+		}
+		state := s.state
+		s.notify()
+		s.mu.Unlock()
+
+		switch state {
+		case "running":
+		case "waiting":
+			select {
+			case <-s.wake:
+			case <-ctx.Done():
+				s.mu.Lock()
+				s.state = "stopped"
+				s.notify()
+				s.mu.Unlock()
+				log.Info("session stopped", zap.String("session", s.id))
+				return
+			}
+		default:
+			log.Info("session finished", zap.String("session", s.id), zap.String("state", state), zap.Error(s.err))
+			return
+		}
+	}
+}
+
+// execute runs a chunk of instructions. A program fault, like an unknown instruction, is returned instead of
+// taking the server down.
+func (s *session) execute() (stop bool, fault error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stop, fault = true, fmt.Errorf("%v", r)
+		}
+	}()
+	for i := 0; i < sessionChunk && !stop; i++ {
+		stop = s.c.E()
+	}
+	return stop, nil
+}
+
+// notify wakes every output stream, the caller holds the lock
+func (s *session) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// finished reports whether the session stopped for good, the caller holds the lock
+func (s *session) finished() bool {
+	return s.state != "running" && s.state != "waiting"
+}
+
+func (s *session) status() sessionStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := sessionStatus{ID: s.id, State: s.state, IP: s.c.IP(), Steps: s.c.Steps(), Outputs: len(s.outputs)}
+	if s.err != nil {
+		st.Error = s.err.Error()
+	}
+	return st
+}
+
+func (s *session) input(w http.ResponseWriter, r *http.Request) error {
+	var req struct {
+		Values []int `json:"values"`
+		Close  bool  `json:"close"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errorf(http.StatusBadRequest, "decode request: %v", err)
+	}
+
+	s.mu.Lock()
+	if s.finished() {
+		s.mu.Unlock()
+		return errorf(http.StatusConflict, "session %s is %s", s.id, s.state)
+	}
+	s.c.Send(req.Values...)
+	if req.Close {
+		s.c.CloseInput()
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	writeJSON(w, http.StatusOK, s.status())
+	return nil
+}
+
+// streamOutput sends every output as an "output" event and the final state as a "state" event
+func (s *session) streamOutput(w http.ResponseWriter, r *http.Request) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errorf(http.StatusInternalServerError, "streaming is not supported")
+	}
+	from := 0
+	if v := r.URL.Query().Get("from"); v != "" {
+		var err error
+		if from, err = strconv.Atoi(v); err != nil || from < 0 {
+			return errorf(http.StatusBadRequest, "invalid from %q", v)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		s.mu.Lock()
+		var pending []int
+		if from < len(s.outputs) {
+			pending = append(pending, s.outputs[from:]...)
+		}
+		finished, state, changed := s.finished(), s.state, s.changed
+		s.mu.Unlock()
+
+		for _, val := range pending {
+			fmt.Fprintf(w, "id: %d\nevent: output\ndata: %d\n\n", from, val)
+			from++
+		}
+		if finished {
+			fmt.Fprintf(w, "event: state\ndata: %q\n\n", state)
+			flusher.Flush()
+			return nil
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (s *session) snapshot(w http.ResponseWriter, r *http.Request) error {
+	s.mu.Lock()
+	state := s.c.Snapshot()
+	s.mu.Unlock()
+
+	if r.Header.Get("Accept") == "application/octet-stream" {
+		var buf bytes.Buffer
+		if err := intcode.EncodeState(&buf, state); err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+	writeJSON(w, http.StatusOK, state)
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warn("failed to write response", zap.Error(err))
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// doubler outputs every input doubled until its input closes
+const doubler = "3,11,1002,11,2,12,4,12,1105,1,0,0,0"
+
+// request sends the body as JSON, or as is when it is a string, and decodes the response into out
+func request(t *testing.T, method, url string, body interface{}, wantCode int, out interface{}) {
+	t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantCode {
+		data, _ := ioutil.ReadAll(resp.Body)
+		t.Fatalf("%s %s: status %d, want %d: %s", method, url, resp.StatusCode, wantCode, data)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// event is a server-sent event
+type event struct {
+	name, data string
+}
+
+// streamEvents reads the output stream of the session until the server ends it
+func streamEvents(t *testing.T, url string) []event {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type %q", ct)
+	}
+
+	var events []event
+	var e event
+	s := bufio.NewScanner(resp.Body)
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case line == "":
+			events = append(events, e)
+			e = event{}
+		case strings.HasPrefix(line, "event: "):
+			e.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestServeSessions(t *testing.T) {
+	ts := httptest.NewServer(newServer(intcode.Limits{MaxSteps: 100000}))
+	defer ts.Close()
+
+	var program struct {
+		ID     string `json:"id"`
+		Length int    `json:"length"`
+	}
+	request(t, http.MethodPost, ts.URL+"/programs", doubler, http.StatusCreated, &program)
+	if program.Length != 13 {
+		t.Fatalf("uploaded %d values, want 13", program.Length)
+	}
+
+	tests := []struct {
+		name   string
+		create map[string]interface{}
+		input  map[string]interface{}
+		events []event
+	}{
+		{"uploaded program", map[string]interface{}{"program": program.ID, "inputs": []int{1}},
+			map[string]interface{}{"values": []int{2, 3}, "close": true},
+			[]event{{"output", "2"}, {"output", "4"}, {"output", "6"}, {"state", `"input closed"`}}},
+		{"inline code", map[string]interface{}{"code": []int{104, 7, 99}}, nil,
+			[]event{{"output", "7"}, {"state", `"halted"`}}},
+		{"inputs up front", map[string]interface{}{"code": []int{3, 11, 1002, 11, 2, 12, 4, 12, 1105, 1, 0, 0, 0},
+			"inputs": []int{5}, "close": true}, nil,
+			[]event{{"output", "10"}, {"state", `"input closed"`}}},
+		{"faulting program", map[string]interface{}{"code": []int{104, 1, 42}}, nil,
+			[]event{{"output", "1"}, {"state", `"faulted"`}}},
+		{"killed by a limit", map[string]interface{}{"code": []int{1105, 1, 0}}, nil,
+			[]event{{"state", `"killed"`}}},
+		{"killed by the default memory limit", map[string]interface{}{"code": []int{1101, 1, 1, 2000000, 99}}, nil,
+			[]event{{"state", `"killed"`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status sessionStatus
+			request(t, http.MethodPost, ts.URL+"/sessions", tt.create, http.StatusCreated, &status)
+			if status.ID == "" {
+				t.Fatalf("session without an id")
+			}
+			if tt.input != nil {
+				request(t, http.MethodPost, ts.URL+"/sessions/"+status.ID+"/input", tt.input, http.StatusOK, nil)
+			}
+
+			events := streamEvents(t, ts.URL+"/sessions/"+status.ID+"/output")
+			if !reflect.DeepEqual(events, tt.events) {
+				t.Errorf("events %v, want %v", events, tt.events)
+			}
+			// the stream starts over from any output
+			if len(tt.events) > 2 {
+				events = streamEvents(t, ts.URL+"/sessions/"+status.ID+"/output?from=2")
+				if !reflect.DeepEqual(events, tt.events[2:]) {
+					t.Errorf("events from 2 %v, want %v", events, tt.events[2:])
+				}
+			}
+
+			request(t, http.MethodGet, ts.URL+"/sessions/"+status.ID, nil, http.StatusOK, &status)
+			if want := strings.Trim(tt.events[len(tt.events)-1].data, `"`); status.State != want {
+				t.Errorf("state %q, want %q", status.State, want)
+			}
+			if (status.State == "faulted" || status.State == "killed") == (status.Error == "") {
+				t.Errorf("state %q with error %q", status.State, status.Error)
+			}
+			// a finished session takes no more input
+			request(t, http.MethodPost, ts.URL+"/sessions/"+status.ID+"/input", map[string]interface{}{"values": []int{1}},
+				http.StatusConflict, nil)
+		})
+	}
+
+	var statuses []sessionStatus
+	request(t, http.MethodGet, ts.URL+"/sessions", nil, http.StatusOK, &statuses)
+	if len(statuses) != len(tests) {
+		t.Errorf("listed %d sessions, want %d", len(statuses), len(tests))
+	}
+}
+
+func TestServeSnapshotAndHalt(t *testing.T) {
+	ts := httptest.NewServer(newServer(intcode.Limits{}))
+	defer ts.Close()
+
+	var status sessionStatus
+	request(t, http.MethodPost, ts.URL+"/sessions", map[string]interface{}{"code": []int{3, 11, 1002, 11, 2, 12, 4,
+		12, 1105, 1, 0, 0, 0}, "inputs": []int{4}}, http.StatusCreated, &status)
+	url := ts.URL + "/sessions/" + status.ID
+
+	// wait for the session to run out of input
+	var state intcode.State
+	for !state.Waiting {
+		request(t, http.MethodGet, url+"/snapshot", nil, http.StatusOK, &state)
+	}
+	if !reflect.DeepEqual(state.Output, []int(nil)) || state.Memory[12] != 8 {
+		t.Errorf("snapshot output %v and memory %v", state.Output, state.Memory[:13])
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url+"/snapshot", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := intcode.DecodeState(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Memory, state.Memory) || decoded.IP != state.IP {
+		t.Errorf("binary snapshot differs from the JSON snapshot")
+	}
+
+	// a session restored from the snapshot continues where it left off
+	var restored sessionStatus
+	request(t, http.MethodPost, ts.URL+"/sessions", map[string]interface{}{"state": state, "inputs": []int{5},
+		"close": true}, http.StatusCreated, &restored)
+	events := streamEvents(t, ts.URL+"/sessions/"+restored.ID+"/output")
+	if want := []event{{"output", "10"}, {"state", `"input closed"`}}; !reflect.DeepEqual(events, want) {
+		t.Errorf("restored session events %v, want %v", events, want)
+	}
+
+	request(t, http.MethodPost, url+"/halt", nil, http.StatusOK, nil)
+	events = streamEvents(t, url+"/output")
+	if want := []event{{"output", "8"}, {"state", `"stopped"`}}; !reflect.DeepEqual(events, want) {
+		t.Errorf("halted session events %v, want %v", events, want)
+	}
+	request(t, http.MethodDelete, url, nil, http.StatusNoContent, nil)
+	request(t, http.MethodGet, url, nil, http.StatusNotFound, nil)
+}
+
+func TestServeErrors(t *testing.T) {
+	ts := httptest.NewServer(newServer(intcode.Limits{}))
+	defer ts.Close()
+
+	tests := []struct {
+		method, path string
+		body         interface{}
+		code         int
+	}{
+		{http.MethodPost, "/programs", "1,,2", http.StatusBadRequest},
+		{http.MethodGet, "/programs/p9", nil, http.StatusNotFound},
+		{http.MethodPost, "/sessions", "{", http.StatusBadRequest},
+		{http.MethodPost, "/sessions", map[string]interface{}{}, http.StatusBadRequest},
+		{http.MethodPost, "/sessions", map[string]interface{}{"program": "p9"}, http.StatusNotFound},
+		{http.MethodGet, "/sessions/s9", nil, http.StatusNotFound},
+		{http.MethodPut, "/sessions", nil, http.StatusNotFound},
+		{http.MethodGet, "/nothing", nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		var body map[string]string
+		request(t, tt.method, ts.URL+tt.path, tt.body, tt.code, &body)
+		if body["error"] == "" {
+			t.Errorf("%s %s: no error message", tt.method, tt.path)
+		}
+	}
+}
+
+func TestServeHalt(t *testing.T) {
+	ts := httptest.NewServer(newServer(intcode.Limits{}))
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		create map[string]interface{}
+		// wait is the state to wait for before halting
+		wait string
+		want string
+	}{
+		{"waiting", map[string]interface{}{"code": []int{3, 5, 1105, 1, 0, 0}}, "waiting", "stopped"},
+		{"running", map[string]interface{}{"code": []int{1105, 1, 0}}, "running", "stopped"},
+		{"halted", map[string]interface{}{"code": []int{99}}, "halted", "halted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status sessionStatus
+			request(t, http.MethodPost, ts.URL+"/sessions", tt.create, http.StatusCreated, &status)
+			url := ts.URL + "/sessions/" + status.ID
+			for status.State != tt.wait {
+				request(t, http.MethodGet, url, nil, http.StatusOK, &status)
+			}
+			// the response already has the state the session stopped in
+			request(t, http.MethodPost, url+"/halt", nil, http.StatusOK, &status)
+			if status.State != tt.want {
+				t.Errorf("halted in state %q, want %q", status.State, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// patchFlags collects repeated -patch address=min..max flags
+type patchFlags []intcode.Patch
+
+func (p *patchFlags) String() string {
+	var parts []string
+	for _, patch := range *p {
+		parts = append(parts, fmt.Sprintf("%d=%d..%d", patch.Address, patch.Min, patch.Max))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p *patchFlags) Set(value string) error {
+	address, valueRange, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("patch %q is not address=min..max", value)
+	}
+	min, max, ok := strings.Cut(valueRange, "..")
+	if !ok {
+		min, max = valueRange, valueRange
+	}
+
+	var patch intcode.Patch
+	var err error
+	if patch.Address, err = strconv.Atoi(address); err != nil {
+		return err
+	}
+	if patch.Min, err = strconv.Atoi(min); err != nil {
+		return err
+	}
+	if patch.Max, err = strconv.Atoi(max); err != nil {
+		return err
+	}
+	*p = append(*p, patch)
+	return nil
+}
+
+// sweep runs a program for every combination of patched memory values and prints the matching ones
+func sweep(args []string) error {
+	flags := flag.NewFlagSet("sweep", flag.ExitOnError)
+	var patches patchFlags
+	flags.Var(&patches, "patch", "patch `address=min..max`, may be repeated")
+	memTarget := flags.String("mem", "", "match runs whose final memory holds `address=value`")
+	outTarget := flags.String("output", "", "match runs whose last output is `value`")
+	inputs := flags.String("input", "", "comma separated `values` sent to every run")
+	all := flags.Bool("all", false, "print every match instead of the first one")
+	workers := flags.Int("workers", 0, "number of parallel runs, GOMAXPROCS when zero")
+	maxSteps := flags.Int("max-steps", 0, "kill runs after `n` instructions, no limit when zero")
+	maxAddress := flags.Int("max-address", 0, "kill runs that access memory above `address`, no limit when zero")
+	timeout := flags.Duration("timeout", 0, "stop the sweep after `duration`, no limit when zero")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode sweep -patch address=min..max... (-mem address=value | -output value) program\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || len(patches) == 0 {
+		flags.Usage()
+		return errors.New("sweep needs a program and at least one patch")
+	}
+
+	program, err := intcode.LoadProgram(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	s := intcode.Sweep{Program: program, Patches: patches, First: !*all, Workers: *workers, Logger: log}
+	s.Limits = intcode.Limits{MaxSteps: *maxSteps, MaxAddress: *maxAddress}
+	if *inputs != "" {
+		if s.Inputs, err = intcode.ParseProgram(*inputs); err != nil {
+			return fmt.Errorf("input: %v", err)
+		}
+	}
+	switch {
+	case *memTarget != "" && *outTarget != "":
+		return errors.New("use either -mem or -output")
+	case *memTarget != "":
+		address, val, ok := strings.Cut(*memTarget, "=")
+		if !ok {
+			return fmt.Errorf("-mem %q is not address=value", *memTarget)
+		}
+		a, err := strconv.Atoi(address)
+		if err != nil {
+			return err
+		}
+		v, err := strconv.Atoi(val)
+		if err != nil {
+			return err
+		}
+		s.Target = intcode.MemoryEquals(a, v)
+	case *outTarget != "":
+		v, err := strconv.Atoi(*outTarget)
+		if err != nil {
+			return err
+		}
+		s.Target = intcode.LastOutputEquals(v)
+	default:
+		return errors.New("sweep needs a -mem or -output target")
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	matches, err := s.Run(ctx)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return intcode.ErrNoMatch
+	}
+	for _, values := range matches {
+		var parts []string
+		for i, val := range values {
+			parts = append(parts, fmt.Sprintf("%d=%d", patches[i].Address, val))
+		}
+		fmt.Println(strings.Join(parts, " "))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"os"
+	"strings"
+)
+
+// taint runs a program and reports which inputs every output depends on
+func taint(args []string) error {
+	flags := flag.NewFlagSet("taint", flag.ExitOnError)
+	output := flags.String("o", "-", "output file, - for stdout")
+	var inputs inputFlags
+	flags.Var(&inputs, "input", "comma separated input `values`")
+	labels := flags.String("labels", "", "comma separated `names` of the inputs, in0, in1... by default")
+	maxSteps := flags.Int("max-steps", 10000000, "stop after `n` instructions, 0 for no limit")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode taint [-o output] [-input values] [-labels names] [-max-steps n] program\n\n"+
+			"Labels every input value and follows the labels through arithmetic, comparisons and memory. Every output\n"+
+			"lists the inputs its value was computed from (data) and the inputs that decided a conditional jump\n"+
+			"before it (control), with the addresses of those jumps.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("taint needs exactly one program")
+	}
+	program, err := intcode.LoadProgram(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if *labels != "" {
+		for _, name := range strings.Split(*labels, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	t := intcode.NewTaint(names...)
+	c := intcode.NewComputer(program, intcode.WithTracer(t), intcode.WithLimits(intcode.Limits{MaxSteps: *maxSteps}))
+	for _, input := range inputs {
+		c.Send(input...)
+	}
+	c.CloseInput()
+	result, err := c.Run()
+
+	var out bytes.Buffer
+	for _, o := range t.Outputs() {
+		fmt.Fprintf(&out, "output %d = %d\n  data:    %s\n  control: %s", o.Index, o.Value, labelList(o.Data), labelList(o.Control))
+		if len(o.Jumps) > 0 {
+			fmt.Fprintf(&out, " (jumps at %s)", strings.Trim(fmt.Sprint(o.Jumps), "[]"))
+		}
+		fmt.Fprintln(&out)
+	}
+	if err != nil {
+		fmt.Fprintf(&out, "stopped: %v\n", err)
+	}
+	fmt.Fprintf(&out, "%v\n", result)
+	return writeFile(*output, out.Bytes())
+}
+
+func labelList(labels []string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	return strings.Join(labels, ", ")
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"strings"
+)
+
+// tracePrinter prints executed instructions, or only calls and returns, indented by the call depth
+type tracePrinter struct {
+	w     io.Writer
+	stack *intcode.CallStack
+	calls bool
+}
+
+func (t *tracePrinter) Trace(c intcode.Inspector, e intcode.Event) {
+	depth := t.stack.Depth()
+	t.stack.Trace(c, e)
+	indent := strings.Repeat("  ", depth-1)
+	if !t.calls {
+		fmt.Fprintf(t.w, "%10d %6d rb %-6d %s%s\n", e.Step, e.IP, e.RelativeBase, indent, eventText(c, e))
+		return
+	}
+	switch newDepth := t.stack.Depth(); {
+	case newDepth > depth:
+		f := t.stack.Frames()[0]
+		fmt.Fprintf(t.w, "%10d %6d %scall %s, returns to %d\n", e.Step, e.IP, indent, f.Name(), f.ReturnAddress)
+	case newDepth < depth:
+		fmt.Fprintf(t.w, "%10d %6d %sreturn to %d\n", e.Step, e.IP, strings.Repeat("  ", newDepth-1), e.NextIP)
+	}
+}
+
+// eventText disassembles the executed instruction, its parameters are read from memory after it ran
+func eventText(c intcode.Inspector, e intcode.Event) string {
+	cells := []int{e.Instruction, 0, 0, 0}
+	for i := 1; i < len(cells); i++ {
+		cells[i], _ = c.Int(e.IP + i)
+	}
+	inst, err := intcode.Decode(cells, 0)
+	if err != nil {
+		return fmt.Sprintf("data %d", e.Instruction)
+	}
+	return inst.String()
+}
+
+// logOptions makes the computer log to stderr with the levels of a log config, see intcode.ParseLogConfig
+func logOptions(levels string) ([]intcode.Option, error) {
+	config, err := intcode.ParseLogConfig(levels)
+	if err != nil {
+		return nil, err
+	}
+	cfg := zap.NewDevelopmentConfig()
+	cfg.DisableStacktrace = true
+	logger, err := cfg.Build()
+	if err != nil {
+		return nil, err
+	}
+	return []intcode.Option{intcode.WithLogger(logger), intcode.WithLogConfig(config)}, nil
+}
+
+// trace runs a program and prints every instruction it executes, and a backtrace when it stops early
+func trace(args []string) error {
+	flags := flag.NewFlagSet("trace", flag.ExitOnError)
+	output := flags.String("o", "-", "output file, - for stdout")
+	var inputs inputFlags
+	flags.Var(&inputs, "input", "comma separated input `values`")
+	calls := flags.Bool("calls", false, "only print calls and returns")
+	maxSteps := flags.Int("max-steps", 10000000, "stop after `n` instructions, 0 for no limit")
+	specName := flags.String("spec", "day09", "stop at features beyond the spec `level`: day02, day05, day09 or day11")
+	logLevels := flags.String("log", "", "log the computer to stderr with `levels` like info,exec=debug,io=debug")
+	jsonl := flags.Bool("json", false, "write a JSON record per instruction for tracediff, the rest of the report goes to stderr")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode trace [-o output] [-input values] [-calls] [-max-steps n] [-spec level] [-log levels] [-json] program\n\n"+
+			"Prints the step, address, relative base and instruction of everything the program executes, indented by\n"+
+			"the call depth inferred from the relative base. A fault or limit prints a backtrace. The log subsystems\n"+
+			"are exec, io, limits and native.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("trace needs exactly one program")
+	}
+	spec, err := intcode.ParseSpec(*specName)
+	if err != nil {
+		return err
+	}
+	program, err := intcode.LoadProgram(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	stack := intcode.NewCallStack()
+	var tracer intcode.Tracer = &tracePrinter{w: bw, stack: stack, calls: *calls}
+	report := io.Writer(bw)
+	opts := []intcode.Option{
+		intcode.WithLimits(intcode.Limits{MaxSteps: *maxSteps}),
+		intcode.WithSpec(spec),
+	}
+	var records *intcode.JSONTracer
+	if *jsonl {
+		// the printer follows the calls otherwise
+		records = intcode.NewJSONTracer(bw)
+		tracer, report = records, os.Stderr
+		opts = append(opts, intcode.WithTracer(stack))
+	}
+	opts = append(opts, intcode.WithTracer(tracer))
+	if *logLevels != "" {
+		logOpts, err := logOptions(*logLevels)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, logOpts...)
+	}
+	c := intcode.NewComputer(program, opts...)
+	for _, input := range inputs {
+		c.Send(input...)
+	}
+	c.CloseInput()
+
+	result, err := c.Run()
+	if records != nil && records.Err() != nil {
+		return records.Err()
+	}
+	for _, val := range c.TakeOutput() {
+		fmt.Fprintf(report, "output %d\n", val)
+	}
+	switch result.Reason {
+	case intcode.HaltInputEOF:
+		fmt.Fprintf(report, "stopped: the program wanted input after the input was closed\n")
+	case intcode.HaltLimit, intcode.HaltFault:
+		fmt.Fprintf(report, "stopped: %v\n%s", err, stack.Backtrace())
+	}
+	fmt.Fprintf(report, "%v\n", result)
+	return bw.Flush()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"os"
+)
+
+// tracediff finds where two JSONL traces of runs diverge
+func tracediff(args []string) error {
+	flags := flag.NewFlagSet("tracediff", flag.ExitOnError)
+	output := flags.String("o", "-", "report file, - for stdout")
+	context := flags.Int("context", 3, "show `n` records before and after the divergence")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode tracediff [-o report] [-context n] a.jsonl b.jsonl\n\n"+
+			"Aligns two traces written by intcode trace -json, or any interpreter writing the same records, step by\n"+
+			"step. Reports the first record that differs in its instruction, relative base, memory writes or output\n"+
+			"with the records around it, where the traces realign, and how the memory writes and outputs after the\n"+
+			"divergence differ.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("tracediff needs exactly two traces")
+	}
+
+	var traces [2][]intcode.TraceRecord
+	for i := range traces {
+		f, err := os.Open(flags.Arg(i))
+		if err != nil {
+			return err
+		}
+		traces[i], err = intcode.ReadTrace(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("read %s: %v", flags.Arg(i), err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := intcode.DiffTraces(traces[0], traces[1], *context).Write(&out); err != nil {
+		return err
+	}
+	return writeFile(*output, out.Bytes())
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"os"
+)
+
+// transpile writes Go source for a program
+func transpile(args []string) error {
+	flags := flag.NewFlagSet("transpile", flag.ExitOnError)
+	output := flags.String("o", "-", "output file, - for stdout")
+	pkg := flags.String("package", "main", "`package` of the generated file")
+	name := flags.String("var", "Program", "`name` of the generated *intcode.Compiled variable")
+	optimized := flags.Bool("O", false, "translate the instructions as rewritten by the optimizer")
+	var inputs inputFlags
+	flags.Var(&inputs, "input", "comma separated `values` for a verification run of -O, may be repeated")
+	maxSteps := flags.Int("max-steps", 10000000, "stop verification runs after `n` instructions")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: intcode transpile [-O [-input values]... [-max-steps n]] [-o output] [-package package] [-var name] program\n\n"+
+			"Writes Go source for the program, run it with Computer.RunNative. With -O the optimized program runs next to\n"+
+			"the original for every -input, or once without input, and must behave the same.\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("transpile needs exactly one program")
+	}
+
+	program, err := intcode.LoadProgram(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err := intcode.Transpile(&out, program, intcode.TranspileOptions{Package: *pkg, Var: *name, Optimize: *optimized,
+		Inputs: inputs, Limits: intcode.Limits{MaxSteps: *maxSteps}}); err != nil {
+		return err
+	}
+	return writeFile(*output, out.Bytes())
+}
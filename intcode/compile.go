@@ -0,0 +1,782 @@
+package intcode
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The intcode language is a small C-like language with integer variables and functions:
+//
+//	// comments run to the end of the line
+//	var total = 0;                  // globals are declared at the top level
+//	fn fib(n) {
+//	    if n < 2 {
+//	        return n;
+//	    }
+//	    return fib(n - 1) + fib(n - 2);
+//	}
+//	var n = input();                // reads a value
+//	while n > 0 {
+//	    output fib(n);              // writes a value
+//	    n = n - 1;
+//	}
+//
+// Statements at the top level form the main program, which halts at the end. Expressions have + - * with unary
+// - and !, the comparisons == != < > <= >= and && || which always evaluate both sides. Functions return zero without
+// a return statement.
+//
+// Globals are memory cells accessed in position mode, constants are immediates and parameters, locals and
+// temporaries live in a stack frame accessed in relative mode. A caller stores the return address at [rb] and the
+// arguments above it and jumps to the function, which moves the relative base up by its frame size. Return values
+// pass through a global cell. This is the convention CallStack recognizes.
+
+// CompileError reports invalid source of the intcode language
+type CompileError struct {
+	// Line starts at 1
+	Line int
+	Msg  string
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Compile translates source of the intcode language into a program. The source map relates the program to the
+// source lines and asm is the generated assembler source, which has the source line of every instruction as a
+// comment.
+func Compile(r io.Reader, path string) (program []int, m *SourceMap, asm string, err error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	toks, err := lex(string(src))
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	c := &compiler{toks: toks, globals: make(map[string]bool), funcs: make(map[string]*compiledFunc)}
+	if err := c.compileProgram(); err != nil {
+		return nil, nil, "", err
+	}
+	asm, srcLines := c.render()
+
+	program, asmMap, err := Assemble(strings.NewReader(asm), "")
+	if err != nil {
+		return nil, nil, asm, fmt.Errorf("assemble generated code: %v", err)
+	}
+	m = NewSourceMap(path)
+	addresses := make([]int, 0, len(asmMap.lines))
+	for address := range asmMap.lines {
+		addresses = append(addresses, address)
+	}
+	sort.Ints(addresses)
+	for _, address := range addresses {
+		if line, ok := srcLines[asmMap.lines[address]]; ok {
+			m.Add(address, line)
+		}
+	}
+	return program, m, asm, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// punctuation lists the operators, longer ones first
+var punctuation = []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "<", ">", "!", "=", "(", ")", "{", "}", ",", ";"}
+
+var keywords = map[string]bool{"var": true, "fn": true, "if": true, "else": true, "while": true, "return": true, "output": true, "input": true}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	line := 1
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == '\n':
+			line++
+			i++
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case ch >= '0' && ch <= '9':
+			start := i
+			for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+				i++
+			}
+			toks = append(toks, token{tokNumber, src[start:i], line})
+		case ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z':
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] >= 'a' && src[i] <= 'z' || src[i] >= 'A' && src[i] <= 'Z' || src[i] >= '0' && src[i] <= '9') {
+				i++
+			}
+			toks = append(toks, token{tokIdent, src[start:i], line})
+		default:
+			found := false
+			for _, p := range punctuation {
+				if strings.HasPrefix(src[i:], p) {
+					toks = append(toks, token{tokPunct, p, line})
+					i += len(p)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, &CompileError{line, fmt.Sprintf("unexpected character %q", ch)}
+			}
+		}
+	}
+	return append(toks, token{tokEOF, "", line}), nil
+}
+
+// operand is an instruction operand rendered once the frame size of its function is known
+type operand interface {
+	render(frame int) string
+}
+
+// immediate is a constant
+type immediate int
+
+func (o immediate) render(int) string { return strconv.Itoa(int(o)) }
+
+// address is a label used as an immediate value
+type address string
+
+func (o address) render(int) string { return string(o) }
+
+// global is a memory cell at a label, accessed in position mode
+type global string
+
+func (o global) render(int) string { return "[" + string(o) + "]" }
+
+// slot is a cell of the stack frame. Slot 0 holds the return address, the parameters follow, then locals and
+// temporaries. The relative base points just above the last slot.
+type slot int
+
+func (o slot) render(frame int) string { return fmt.Sprintf("[rb%+d]", int(o)-frame) }
+
+// outgoing is a cell above the frame, where calls put the return address and arguments
+type outgoing int
+
+func (o outgoing) render(int) string {
+	if o == 0 {
+		return "[rb]"
+	}
+	return fmt.Sprintf("[rb+%d]", int(o))
+}
+
+// frameSize moves the relative base by the frame size, up or down
+type frameSize int
+
+func (o frameSize) render(frame int) string { return strconv.Itoa(int(o) * frame) }
+
+// stackBase is the start of the stack plus the frame of the main program
+type stackBase struct{}
+
+func (stackBase) render(frame int) string { return fmt.Sprintf("__stack+%d", frame) }
+
+type asmInst struct {
+	label    string
+	mnemonic string
+	operands []operand
+	line     int
+}
+
+type compiledFunc struct {
+	name   string
+	params int
+	line   int
+	code   []asmInst
+	scopes []map[string]slot
+	// next is the first free slot, temporaries are allocated above it and released after every statement
+	next     int
+	temps    int
+	maxSlots int
+	defined  bool
+}
+
+type compiler struct {
+	toks []token
+	pos  int
+
+	globals map[string]bool
+	// globalOrder keeps the data layout stable
+	globalOrder []string
+	funcs       map[string]*compiledFunc
+	funcOrder   []*compiledFunc
+	main        *compiledFunc
+	fn          *compiledFunc
+	labels      int
+	line        int
+}
+
+func (c *compiler) errorf(format string, args ...interface{}) error {
+	return &CompileError{c.peek().line, fmt.Sprintf(format, args...)}
+}
+
+func (c *compiler) peek() token {
+	return c.toks[c.pos]
+}
+
+func (c *compiler) next() token {
+	t := c.toks[c.pos]
+	if t.kind != tokEOF {
+		c.pos++
+	}
+	return t
+}
+
+func (c *compiler) accept(text string) bool {
+	t := c.peek()
+	if (t.kind == tokPunct || t.kind == tokIdent) && t.text == text {
+		c.pos++
+		return true
+	}
+	return false
+}
+
+func (c *compiler) expect(text string) error {
+	if !c.accept(text) {
+		return c.errorf("expected %q, found %s", text, describe(c.peek()))
+	}
+	return nil
+}
+
+func (c *compiler) ident() (string, error) {
+	t := c.peek()
+	if t.kind != tokIdent || keywords[t.text] {
+		return "", c.errorf("expected a name, found %s", describe(t))
+	}
+	c.pos++
+	return t.text, nil
+}
+
+func describe(t token) string {
+	if t.kind == tokEOF {
+		return "the end of the source"
+	}
+	return strconv.Quote(t.text)
+}
+
+func (c *compiler) label() string {
+	c.labels++
+	return fmt.Sprintf("L%d", c.labels)
+}
+
+func (c *compiler) emit(mnemonic string, operands ...operand) {
+	c.fn.code = append(c.fn.code, asmInst{mnemonic: mnemonic, operands: operands, line: c.line})
+}
+
+// place puts a label on the next instruction
+func (c *compiler) place(label string) {
+	c.fn.code = append(c.fn.code, asmInst{label: label, line: c.line})
+}
+
+func (c *compiler) temp() slot {
+	s := slot(c.fn.next + c.fn.temps)
+	c.fn.temps++
+	if int(s)+1 > c.fn.maxSlots {
+		c.fn.maxSlots = int(s) + 1
+	}
+	return s
+}
+
+func (c *compiler) lookup(name string) (operand, bool) {
+	for i := len(c.fn.scopes) - 1; i >= 0; i-- {
+		if s, ok := c.fn.scopes[i][name]; ok {
+			return s, true
+		}
+	}
+	if c.globals[name] {
+		return global("var_" + name), true
+	}
+	return nil, false
+}
+
+func newFunc(name string, params, line int) *compiledFunc {
+	return &compiledFunc{name: name, params: params, line: line, scopes: []map[string]slot{{}}, next: 1 + params, maxSlots: 1 + params}
+}
+
+func (c *compiler) compileProgram() error {
+	c.main = newFunc("main", 0, 1)
+	c.fn = c.main
+	for c.peek().kind != tokEOF {
+		if c.peek().text == "fn" && c.peek().kind == tokIdent {
+			if err := c.function(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.statement(); err != nil {
+			return err
+		}
+	}
+	c.line = c.peek().line
+	c.emit("halt")
+	for _, f := range c.funcOrder {
+		if !f.defined {
+			return &CompileError{f.line, fmt.Sprintf("function %s is not defined", f.name)}
+		}
+	}
+	return nil
+}
+
+// function compiles a function definition
+func (c *compiler) function() error {
+	line := c.next().line
+	name, err := c.ident()
+	if err != nil {
+		return err
+	}
+	if err := c.expect("("); err != nil {
+		return err
+	}
+	var params []string
+	for !c.accept(")") {
+		if len(params) > 0 {
+			if err := c.expect(","); err != nil {
+				return err
+			}
+		}
+		param, err := c.ident()
+		if err != nil {
+			return err
+		}
+		params = append(params, param)
+	}
+
+	f, ok := c.funcs[name]
+	switch {
+	case ok && f.defined:
+		return &CompileError{line, fmt.Sprintf("function %s is defined twice", name)}
+	case ok && f.params != len(params):
+		return &CompileError{line, fmt.Sprintf("function %s is called with %d arguments but has %d parameters", name, f.params, len(params))}
+	case !ok:
+		f = newFunc(name, len(params), line)
+		c.funcs[name] = f
+		c.funcOrder = append(c.funcOrder, f)
+	}
+	f.defined = true
+	f.line = line
+	for i, param := range params {
+		if _, ok := f.scopes[0][param]; ok {
+			return &CompileError{line, fmt.Sprintf("parameter %s is declared twice", param)}
+		}
+		f.scopes[0][param] = slot(1 + i)
+	}
+
+	outer := c.fn
+	c.fn = f
+	defer func() { c.fn = outer }()
+	c.line = line
+	if err := c.block(); err != nil {
+		return err
+	}
+	c.line = c.toks[c.pos-1].line
+	c.emit("add", immediate(0), immediate(0), global("__ret"))
+	c.epilogue()
+	return nil
+}
+
+// epilogue pops the frame and returns to the address stored at its bottom
+func (c *compiler) epilogue() {
+	c.emit("arb", frameSize(-1))
+	c.emit("jt", immediate(1), outgoing(0))
+}
+
+// block compiles statements in braces with their own scope
+func (c *compiler) block() error {
+	if err := c.expect("{"); err != nil {
+		return err
+	}
+	c.fn.scopes = append(c.fn.scopes, map[string]slot{})
+	defer func() { c.fn.scopes = c.fn.scopes[:len(c.fn.scopes)-1] }()
+	for !c.accept("}") {
+		if c.peek().kind == tokEOF {
+			return c.errorf("missing }")
+		}
+		if err := c.statement(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *compiler) statement() error {
+	t := c.peek()
+	c.line = t.line
+	defer func() { c.fn.temps = 0 }()
+
+	switch {
+	case t.kind == tokIdent && t.text == "var":
+		c.next()
+		name, err := c.ident()
+		if err != nil {
+			return err
+		}
+		var value operand = immediate(0)
+		if c.accept("=") {
+			if value, err = c.expr(); err != nil {
+				return err
+			}
+		}
+		var dest operand
+		if c.fn == c.main && len(c.fn.scopes) == 1 {
+			if c.globals[name] {
+				return &CompileError{t.line, fmt.Sprintf("global %s is declared twice", name)}
+			}
+			c.globals[name] = true
+			c.globalOrder = append(c.globalOrder, name)
+			dest = global("var_" + name)
+		} else {
+			scope := c.fn.scopes[len(c.fn.scopes)-1]
+			if _, ok := scope[name]; ok {
+				return &CompileError{t.line, fmt.Sprintf("%s is declared twice", name)}
+			}
+			// the local takes the slot above the parameters and locals, the value's temporaries are released
+			s := slot(c.fn.next)
+			c.fn.next++
+			if c.fn.next > c.fn.maxSlots {
+				c.fn.maxSlots = c.fn.next
+			}
+			scope[name] = s
+			dest = s
+		}
+		c.emit("add", value, immediate(0), dest)
+		return c.expect(";")
+
+	case t.kind == tokIdent && t.text == "if":
+		c.next()
+		end := c.label()
+		for {
+			cond, err := c.expr()
+			if err != nil {
+				return err
+			}
+			next := c.label()
+			c.emit("jf", cond, address(next))
+			c.fn.temps = 0
+			if err := c.block(); err != nil {
+				return err
+			}
+			if c.peek().text != "else" {
+				c.place(next)
+				break
+			}
+			c.emit("jt", immediate(1), address(end))
+			c.place(next)
+			c.next()
+			if !c.accept("if") {
+				if err := c.block(); err != nil {
+					return err
+				}
+				break
+			}
+			c.line = c.toks[c.pos-1].line
+		}
+		c.place(end)
+		return nil
+
+	case t.kind == tokIdent && t.text == "while":
+		c.next()
+		top, end := c.label(), c.label()
+		c.place(top)
+		cond, err := c.expr()
+		if err != nil {
+			return err
+		}
+		c.emit("jf", cond, address(end))
+		c.fn.temps = 0
+		if err := c.block(); err != nil {
+			return err
+		}
+		c.line = t.line
+		c.emit("jt", immediate(1), address(top))
+		c.place(end)
+		return nil
+
+	case t.kind == tokIdent && t.text == "return":
+		c.next()
+		if c.fn == c.main {
+			return c.errorf("return outside of a function")
+		}
+		var value operand = immediate(0)
+		if c.peek().text != ";" {
+			var err error
+			if value, err = c.expr(); err != nil {
+				return err
+			}
+		}
+		c.emit("add", value, immediate(0), global("__ret"))
+		c.epilogue()
+		return c.expect(";")
+
+	case t.kind == tokIdent && t.text == "output":
+		c.next()
+		value, err := c.expr()
+		if err != nil {
+			return err
+		}
+		c.emit("out", value)
+		return c.expect(";")
+
+	case t.kind == tokIdent && c.toks[c.pos+1].text == "=" && !keywords[t.text]:
+		c.next()
+		c.next()
+		dest, ok := c.lookup(t.text)
+		if !ok {
+			return &CompileError{t.line, fmt.Sprintf("%s is not declared", t.text)}
+		}
+		value, err := c.expr()
+		if err != nil {
+			return err
+		}
+		c.emit("add", value, immediate(0), dest)
+		return c.expect(";")
+
+	default:
+		if _, err := c.expr(); err != nil {
+			return err
+		}
+		return c.expect(";")
+	}
+}
+
+// binaryLevels are the binary operators by increasing precedence
+var binaryLevels = [][]string{{"||"}, {"&&"}, {"==", "!=", "<", ">", "<=", ">="}, {"+", "-"}, {"*"}}
+
+func (c *compiler) expr() (operand, error) {
+	return c.binary(0)
+}
+
+func (c *compiler) binary(level int) (operand, error) {
+	if level == len(binaryLevels) {
+		return c.unary()
+	}
+	left, err := c.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, candidate := range binaryLevels[level] {
+			if c.peek().kind == tokPunct && c.peek().text == candidate {
+				op = candidate
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		c.next()
+		right, err := c.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = c.operator(op, left, right)
+	}
+}
+
+// operator emits a binary operation into a new temporary
+func (c *compiler) operator(op string, a, b operand) operand {
+	t := c.temp()
+	switch op {
+	case "+":
+		c.emit("add", a, b, t)
+	case "-":
+		c.emit("mul", b, immediate(-1), t)
+		c.emit("add", a, t, t)
+	case "*":
+		c.emit("mul", a, b, t)
+	case "<":
+		c.emit("lt", a, b, t)
+	case ">":
+		c.emit("lt", b, a, t)
+	case "<=":
+		c.emit("lt", b, a, t)
+		c.emit("eq", t, immediate(0), t)
+	case ">=":
+		c.emit("lt", a, b, t)
+		c.emit("eq", t, immediate(0), t)
+	case "==":
+		c.emit("eq", a, b, t)
+	case "!=":
+		c.emit("eq", a, b, t)
+		c.emit("eq", t, immediate(0), t)
+	case "&&", "||":
+		// with zero tests: a && b is !(a == 0 || b == 0), a || b is !(a == 0 && b == 0)
+		u := c.temp()
+		c.emit("eq", a, immediate(0), t)
+		c.emit("eq", b, immediate(0), u)
+		if op == "&&" {
+			c.emit("add", t, u, t)
+		} else {
+			c.emit("mul", t, u, t)
+		}
+		c.emit("eq", t, immediate(0), t)
+	}
+	return t
+}
+
+func (c *compiler) unary() (operand, error) {
+	switch {
+	case c.accept("-"):
+		value, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := value.(immediate); ok {
+			return -n, nil
+		}
+		t := c.temp()
+		c.emit("mul", value, immediate(-1), t)
+		return t, nil
+	case c.accept("!"):
+		value, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		t := c.temp()
+		c.emit("eq", value, immediate(0), t)
+		return t, nil
+	}
+	return c.primary()
+}
+
+func (c *compiler) primary() (operand, error) {
+	t := c.next()
+	switch {
+	case t.kind == tokNumber:
+		n, err := strconv.Atoi(t.text)
+		if err != nil {
+			return nil, &CompileError{t.line, fmt.Sprintf("invalid number %s", t.text)}
+		}
+		return immediate(n), nil
+	case t.kind == tokPunct && t.text == "(":
+		value, err := c.expr()
+		if err != nil {
+			return nil, err
+		}
+		return value, c.expect(")")
+	case t.kind == tokIdent && t.text == "input":
+		if err := c.expect("("); err != nil {
+			return nil, err
+		}
+		if err := c.expect(")"); err != nil {
+			return nil, err
+		}
+		tmp := c.temp()
+		c.emit("in", tmp)
+		return tmp, nil
+	case t.kind == tokIdent && !keywords[t.text]:
+		if c.accept("(") {
+			return c.call(t)
+		}
+		value, ok := c.lookup(t.text)
+		if !ok {
+			return nil, &CompileError{t.line, fmt.Sprintf("%s is not declared", t.text)}
+		}
+		return value, nil
+	default:
+		c.pos--
+		return nil, c.errorf("expected an expression, found %s", describe(t))
+	}
+}
+
+// call evaluates the arguments, stores them and the return address above the frame and jumps to the function
+func (c *compiler) call(name token) (operand, error) {
+	var args []operand
+	for !c.accept(")") {
+		if len(args) > 0 {
+			if err := c.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := c.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+
+	f, ok := c.funcs[name.text]
+	if !ok {
+		f = newFunc(name.text, len(args), name.line)
+		c.funcs[name.text] = f
+		c.funcOrder = append(c.funcOrder, f)
+	}
+	if f.params != len(args) {
+		return nil, &CompileError{name.line, fmt.Sprintf("function %s has %d parameters, called with %d arguments", name.text, f.params, len(args))}
+	}
+
+	for i, arg := range args {
+		c.emit("add", arg, immediate(0), outgoing(1+i))
+	}
+	ret := c.label()
+	c.emit("add", address(ret), immediate(0), outgoing(0))
+	c.emit("jt", immediate(1), address("fn_"+name.text))
+	c.place(ret)
+	t := c.temp()
+	c.emit("add", global("__ret"), immediate(0), t)
+	return t, nil
+}
+
+// render writes the assembler source and returns the source line of every assembler line
+func (c *compiler) render() (string, map[int]int) {
+	var b strings.Builder
+	srcLines := make(map[int]int)
+	asmLine := 0
+	writeLine := func(text string, line int) {
+		asmLine++
+		if line > 0 {
+			srcLines[asmLine] = line
+			fmt.Fprintf(&b, "%-40s ; line %d\n", text, line)
+		} else {
+			b.WriteString(text + "\n")
+		}
+	}
+
+	writeFunc := func(f *compiledFunc, entry string, first operand) {
+		writeLine(entry+":", 0)
+		writeLine(fmt.Sprintf("        arb  %s", first.render(f.maxSlots)), f.line)
+		for _, inst := range f.code {
+			if inst.label != "" {
+				writeLine(inst.label+":", 0)
+				continue
+			}
+			ops := make([]string, len(inst.operands))
+			for i, op := range inst.operands {
+				ops[i] = op.render(f.maxSlots)
+			}
+			writeLine(strings.TrimRight(fmt.Sprintf("        %-4s %s", inst.mnemonic, strings.Join(ops, ", ")), " "), inst.line)
+		}
+	}
+
+	writeFunc(c.main, "main", stackBase{})
+	for _, f := range c.funcOrder {
+		writeFunc(f, "fn_"+f.name, frameSize(1))
+	}
+	writeLine("__ret:   data 0", 0)
+	for _, name := range c.globalOrder {
+		writeLine(fmt.Sprintf("var_%s: data 0", name), 0)
+	}
+	writeLine("__stack: data 0", 0)
+	return b.String(), srcLines
+}
@@ -0,0 +1,149 @@
+package intcode
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fib is the example of the language documentation
+const fib = `// comments run to the end of the line
+var total = 0;                  // globals are declared at the top level
+fn fib(n) {
+    if n < 2 {
+        return n;
+    }
+    return fib(n - 1) + fib(n - 2);
+}
+var n = input();                // reads a value
+while n > 0 {
+    output fib(n);              // writes a value
+    n = n - 1;
+}
+`
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		input  []int
+		output []int
+	}{
+		{"fibonacci", fib, []int{10}, []int{55, 34, 21, 13, 8, 5, 3, 2, 1, 1}},
+		{"precedence", "output 2 + 3 * 4; output (2 + 3) * 4; output 10 - 2 - 3; output -2 * -3;", nil,
+			[]int{14, 20, 5, 6}},
+		{"comparisons", "output 1 < 2; output 2 <= 1; output 3 == 3; output 3 != 3; output 2 > 1; output 1 >= 2;",
+			nil, []int{1, 0, 1, 0, 1, 0}},
+		{"logic", "output 1 && 0; output 1 || 0; output !0; output !5; output 2 && 3;", nil, []int{0, 1, 1, 0, 1}},
+		{"else if", `fn sign(x) {
+			if x < 0 { return -1; } else if x == 0 { return 0; } else { return 1; }
+		}
+		output sign(input()); output sign(input()); output sign(input());`, []int{-7, 0, 7}, []int{-1, 0, 1}},
+		{"globals and locals", `var g = 1;
+		fn bump(by) { var old = g; g = g + by; return old; }
+		fn nothing() { bump(10); }
+		output bump(2); output g; output nothing(); output g;`, nil, []int{1, 3, 0, 13}},
+		{"scopes", `fn f(a) { var x = a; if a > 0 { var x = 100; output x; } return x; } output f(1);`, nil,
+			[]int{100, 1}},
+		{"nested calls", `fn add(a, b) { return a + b; }
+		fn mul(a, b) { var r = 0; while b > 0 { r = add(r, a); b = b - 1; } return r; }
+		output mul(add(2, 3), add(1, 3));`, nil, []int{20}},
+		{"echo until zero", "var x = input(); while x { output x * 2; x = input(); }", []int{4, -1, 0, 9},
+			[]int{8, -2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, _, asm, err := Compile(strings.NewReader(tt.source), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := NewComputer(program, WithLimits(Limits{MaxSteps: 1000000}))
+			c.Send(tt.input...)
+			c.CloseInput()
+			if result, err := c.Run(); err != nil || result.Reason != HaltOpcode {
+				t.Fatalf("stopped with %v %v", result.Reason, err)
+			}
+			if out := c.TakeOutput(); !reflect.DeepEqual(out, tt.output) {
+				t.Errorf("output %v, want %v from\n%s", out, tt.output, asm)
+			}
+		})
+	}
+}
+
+func TestCompileSourceMap(t *testing.T) {
+	program, m, asm, err := Compile(strings.NewReader(fib), "fib.icl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the generated assembler builds the same program
+	assembled, _, err := Assemble(strings.NewReader(asm), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(assembled, program) {
+		t.Errorf("the assembler source builds a different program")
+	}
+
+	// every statement has code, the call stack sees the recursion of fib
+	for _, line := range []int{2, 5, 7, 9, 11, 12} {
+		if _, ok := m.Address(line); !ok {
+			t.Errorf("line %d has no code", line)
+		}
+	}
+	stack := NewCallStack()
+	deepest := &maxDepth{stack: stack}
+	c := NewComputer(program, WithTracer(stack), WithTracer(deepest))
+	c.Send(6)
+	c.CloseInput()
+	if _, err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if deepest.depth != 7 {
+		t.Errorf("deepest call stack %d, want main and 6 calls of fib", deepest.depth)
+	}
+}
+
+// maxDepth records the deepest call stack
+type maxDepth struct {
+	stack *CallStack
+	depth int
+}
+
+func (d *maxDepth) Trace(c Inspector, e Event) {
+	if d.stack.Depth() > d.depth {
+		d.depth = d.stack.Depth()
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		line   int
+	}{
+		{"unexpected character", "output 1;\noutput 1 # 2;", 2},
+		{"missing semicolon", "output 1\noutput 2;", 2},
+		{"undeclared variable", "var x = 1;\n\nx = y;", 3},
+		{"undeclared assignment", "y = 1;", 1},
+		{"undefined function", "output f(1);", 1},
+		{"wrong argument count", "fn f(a) { return a; }\noutput f(1, 2);", 2},
+		{"function defined twice", "fn f() { }\nfn f() { }", 2},
+		{"parameter declared twice", "fn f(a, a) { }", 1},
+		{"global declared twice", "var x;\nvar x;", 2},
+		{"return in main", "return 1;", 1},
+		{"missing brace", "fn f() {\noutput 1;\n", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := Compile(strings.NewReader(tt.source), "")
+			var compileErr *CompileError
+			if !errors.As(err, &compileErr) {
+				t.Fatalf("error %v, want a CompileError", err)
+			}
+			if compileErr.Line != tt.line {
+				t.Errorf("error on line %d, want %d: %v", compileErr.Line, tt.line, err)
+			}
+		})
+	}
+}
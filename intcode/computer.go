@@ -0,0 +1,597 @@
+package intcode
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// Parameters can be of three types:
+	//   - Position mode:  the arg is the memory address of the value to use ('10' is an address and results in the lookup memory['10'])
+	//   - Immediate mode: the arg should be interpreted as a literal ('15' is the value, 15)
+	//   - Relative mode:  the arg is an offset from the relative base ('10' with a relative base of 5 results in the lookup memory['15'])
+	// NOTE: Parameters that an instruction writes to will never be in immediate mode.
+	ADD               = 1
+	MUL               = 2
+	INPUT             = 3
+	OUTPUT            = 4
+	JMP_IF_TRUE       = 5
+	JMP_IF_FALSE      = 6
+	LESS_THAN         = 7
+	EQUALS            = 8
+	ADJ_RELATIVE_BASE = 9
+	HALT              = 99
+)
+
+const (
+	POSITION_MODE  int = 0
+	IMMEDIATE_MODE int = 1
+	RELATIVE_MODE  int = 2
+)
+
+// InputPolicy decides what an INPUT instruction does when no value is available
+type InputPolicy int
+
+const (
+	// InputBlock waits until a value arrives. Computers owned by a Scheduler cannot block and suspend instead.
+	InputBlock InputPolicy = iota
+	// InputDefault uses the default input value, see WithDefaultInput
+	InputDefault
+	// InputSuspend leaves the instruction pointer on the INPUT instruction and stops execution until E is called again
+	InputSuspend
+)
+
+// Option configures a Machine of any word type
+type Option func(o *options)
+
+// options are the settings of a machine that do not depend on its word type
+type options struct {
+	policy       InputPolicy
+	defaultInput int
+	limits       Limits
+	tracers      []Tracer
+	spec         Spec
+	logger       *zap.Logger
+	logConfig    *LogConfig
+}
+
+// WithInputPolicy sets what an INPUT instruction does when no value is available
+func WithInputPolicy(policy InputPolicy) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// WithDefaultInput serves val whenever no input is available instead of blocking
+func WithDefaultInput(val int) Option {
+	return func(o *options) {
+		o.policy = InputDefault
+		o.defaultInput = val
+	}
+}
+
+// Computer is the machine with Go int words that the puzzles use
+type Computer = Machine[int, IntWords]
+
+// MakeComputer copies the program into a fresh memory space. Nil channels are replaced with buffered channels.
+func MakeComputer(memory []int, input <-chan int, output chan<- int, opts ...Option) *Computer {
+	return MakeMachine[int, IntWords](memory, input, output, opts...)
+}
+
+// NewComputer copies the program into a fresh memory space with its own input and output queues, see Send and
+// TakeOutput. Nothing can arrive while the computer runs, so the InputBlock policy suspends instead.
+func NewComputer(memory []int, opts ...Option) *Computer {
+	return NewMachine[int, IntWords](memory, opts...)
+}
+
+// MakeMachine is MakeComputer for any word type
+func MakeMachine[W any, A Arithmetic[W]](memory []W, input <-chan W, output chan<- W, opts ...Option) *Machine[W, A] {
+	if input == nil {
+		input = make(chan W, 3000)
+	}
+	if output == nil {
+		output = make(chan W, 3000)
+	}
+	var c *Machine[W, A]
+	c = newMachine[W, A](memory,
+		func(block bool) (W, inputStatus) {
+			var zero W
+			if block {
+				var done <-chan struct{}
+				if c.limits.Context != nil {
+					done = c.limits.Context.Done()
+				}
+				// others may pause or inspect the machine while it waits
+				c.ctl.blocked = true
+				c.ctl.leave()
+				var val W
+				ok, cancelled := false, false
+				select {
+				case val, ok = <-input:
+				case <-done:
+					cancelled = true
+				}
+				c.ctl.lock()
+				c.ctl.blocked = false
+				c.ctl.wait()
+				if cancelled {
+					c.exceed(LimitContext, 0, c.limits.Context.Err())
+				}
+				if !ok {
+					return zero, inputEOF
+				}
+				return val, inputReady
+			}
+			select {
+			case val, ok := <-input:
+				if !ok {
+					return zero, inputEOF
+				}
+				return val, inputReady
+			default:
+				return zero, inputPending
+			}
+		},
+		func(val W) {
+			select {
+			case output <- val:
+				return
+			default:
+			}
+			var done <-chan struct{}
+			if c.limits.Context != nil {
+				done = c.limits.Context.Done()
+			}
+			c.ctl.leave()
+			cancelled := false
+			select {
+			case output <- val:
+			case <-done:
+				cancelled = true
+			}
+			c.ctl.enter()
+			if cancelled {
+				c.exceed(LimitContext, 0, c.limits.Context.Err())
+			}
+		},
+		opts...)
+	return c
+}
+
+// NewMachine is NewComputer for any word type
+func NewMachine[W any, A Arithmetic[W]](memory []W, opts ...Option) *Machine[W, A] {
+	c := newMachine[W, A](memory, nil, nil, opts...)
+	c.queued = true
+	c.input = c.readQueue
+	c.output = func(val W) {
+		c.outQueue = append(c.outQueue, val)
+	}
+	return c
+}
+
+// inputStatus is the result of asking an input function for a value
+type inputStatus int
+
+const (
+	// inputReady means the value was read
+	inputReady inputStatus = iota
+	// inputPending means there is no value yet and the computer should suspend on the INPUT instruction
+	inputPending
+	// inputEOF means no more input will ever arrive
+	inputEOF
+)
+
+// newMachine wires the machine to input and output functions. The input function may only block when asked to.
+func newMachine[W any, A Arithmetic[W]](memory []W, input func(block bool) (W, inputStatus), output func(W), opts ...Option) *Machine[W, A] {
+	size := 3000
+	if len(memory) > size {
+		size = len(memory)
+	}
+	mem := make([]W, size)
+	copy(mem, memory)
+	c := Machine[W, A]{memory: mem, input: input, output: output, programLen: len(memory)}
+	for _, opt := range opts {
+		opt(&c.options)
+	}
+	c.logs = newMachineLogs(c.logger, c.logConfig)
+	var done <-chan struct{}
+	if c.limits.Context != nil {
+		done = c.limits.Context.Done()
+	}
+	c.ctl.init(done)
+	return &c
+}
+
+// Machine is the intcode computer over words of type W with the arithmetic A. Addresses, the relative base and
+// opcodes are ints, words that do not fit fault when used as one.
+type Machine[W any, A Arithmetic[W]] struct {
+	options
+	words A
+	logs  machineLogs
+	ctl   control
+
+	relativeBase int
+	ip           int
+	memory       []W
+	input        func(block bool) (W, inputStatus)
+	output       func(W)
+
+	// defaultsServed counts every default input, consecutiveDefaults only those since the last real input or output
+	defaultsServed      int
+	consecutiveDefaults int
+
+	// inputClosed is set when an INPUT instruction finds the input closed
+	inputClosed bool
+	// waiting is set while the computer is suspended on an INPUT instruction
+	waiting bool
+
+	// queued computers are created by NewComputer and own their input and output
+	queued   bool
+	inQueue  []W
+	inClosed bool
+	outQueue []W
+
+	// err is set once a limit or the spec terminated the computer
+	err     error
+	steps   int
+	outputs int
+
+	// programLen is the size of the memory an earlier spec level allows
+	programLen int
+
+	maxAddress      int
+	maxRelativeBase int
+
+	// accessBuf backs the Reads and Writes of trace events
+	accessBuf [4]int
+}
+
+// Send queues input values. It is only valid for computers created with NewComputer.
+func (c *Machine[W, A]) Send(values ...W) {
+	c.mustBeQueued()
+	c.inQueue = append(c.inQueue, values...)
+}
+
+// CloseInput marks the end of the input. An INPUT instruction after the queue drained stops the computer.
+// It is only valid for computers created with NewComputer.
+func (c *Machine[W, A]) CloseInput() {
+	c.mustBeQueued()
+	c.inClosed = true
+}
+
+// TakeOutput removes and returns the queued output values. It is only valid for computers created with NewComputer.
+func (c *Machine[W, A]) TakeOutput() []W {
+	c.mustBeQueued()
+	out := c.outQueue
+	c.outQueue = nil
+	return out
+}
+
+func (c *Machine[W, A]) mustBeQueued() {
+	if !c.queued {
+		panic("computer has no queues, create it with NewComputer")
+	}
+}
+
+func (c *Machine[W, A]) readQueue(block bool) (W, inputStatus) {
+	if len(c.inQueue) > 0 {
+		val := c.inQueue[0]
+		c.inQueue = c.inQueue[1:]
+		return val, inputReady
+	}
+	var zero W
+	if c.inClosed {
+		return zero, inputEOF
+	}
+	return zero, inputPending
+}
+
+// InputClosed reports whether the computer stopped because its input was closed.
+func (c *Machine[W, A]) InputClosed() bool {
+	return c.inputClosed
+}
+
+// Waiting reports whether the computer is suspended on an INPUT instruction. Executing again retries the input.
+func (c *Machine[W, A]) Waiting() bool {
+	return c.waiting
+}
+
+// IP returns the instruction pointer
+func (c *Machine[W, A]) IP() int {
+	return c.ip
+}
+
+// RelativeBase returns the relative base
+func (c *Machine[W, A]) RelativeBase() int {
+	return c.relativeBase
+}
+
+// Peek returns the memory cell at the address without growing memory, zero beyond it
+func (c *Machine[W, A]) Peek(address int) W {
+	if address < 0 || address >= len(c.memory) {
+		var zero W
+		return zero
+	}
+	return c.memory[address]
+}
+
+// MemoryBytes returns the size of the memory in bytes, see Arithmetic.Size
+func (c *Machine[W, A]) MemoryBytes() int {
+	return len(c.memory) * c.words.Size()
+}
+
+// Int returns the memory cell at the address as an int, false when it does not fit. Cells beyond memory are zero.
+func (c *Machine[W, A]) Int(address int) (int, bool) {
+	return c.words.ToInt(c.Peek(address))
+}
+
+// DefaultsServed returns how many times an INPUT instruction used the default input value
+func (c *Machine[W, A]) DefaultsServed() int {
+	return c.defaultsServed
+}
+
+// ConsecutiveDefaults returns how many default input values were served since the computer last received real
+// input or produced output. A computer polling an empty input keeps increasing it, which makes it a cheap idle signal.
+func (c *Machine[W, A]) ConsecutiveDefaults() int {
+	return c.consecutiveDefaults
+}
+
+// Run executes instructions until the program halts, its input is closed, it suspends waiting for input, it
+// exceeds a limit or it faults. The result says which, the error is the LimitError of an exceeded limit, the
+// SpecError of a spec violation or the FaultError of a fault.
+func (c *Machine[W, A]) Run() (RunResult, error) {
+	start, steps, outputs := time.Now(), c.steps, c.outputs
+	err := c.run()
+	return c.result(start, steps, outputs, err), err
+}
+
+// run executes instructions until the computer stops, see Run
+func (c *Machine[W, A]) run() (err error) {
+	defer c.recoverFault(&err)
+	stop := false
+	for !stop {
+		stop = c.E()
+	}
+	return c.Err()
+}
+
+// E the instruction, return true to HALT execution. A computer that exceeded a limit or violated the spec does not
+// execute anymore, a paused one waits for Resume first.
+func (c *Machine[W, A]) E() (stop bool) {
+	c.ctl.enter()
+	defer c.ctl.leave()
+	if c.err != nil {
+		return true
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			limit, ok := r.(limitExceeded)
+			if !ok {
+				panic(r)
+			}
+			c.logs.limits.Debug("computer terminated", zap.Int("instructionPointer", c.ip), zap.Error(limit.err))
+			c.err = limit.err
+			c.waiting = false
+			stop = true
+		}
+	}()
+	c.checkLimits()
+
+	opcode, ok := c.words.ToInt(*c.cell(c.ip))
+	if !ok || opcode < 0 {
+		panic(fmt.Sprintf("instruction %v does not exist at address %d", *c.cell(c.ip), c.ip))
+	}
+	instruction := (opcode/10)%10*10 + (opcode % 10)
+	if c.logs.execEnabled {
+		c.logs.exec.Debug("executing instruction", zap.Int("instructionPointer", c.ip), zap.Int("instruction", opcode),
+			zap.Int("relativeBase", c.relativeBase))
+	}
+	if c.spec != SpecDay09 {
+		c.checkSpec(opcode, instruction)
+	}
+	var event Event
+	if c.tracers != nil {
+		event = Event{IP: c.ip, Instruction: opcode, Opcode: instruction, RelativeBase: c.relativeBase}
+		if instruction == JMP_IF_TRUE || instruction == JMP_IF_FALSE {
+			event.Jumped = !c.words.IsZero(*c.arg(0)) == (instruction == JMP_IF_TRUE)
+		}
+		c.accesses(&event)
+	}
+	switch instruction {
+	case ADD:
+		c.Add()
+	case MUL:
+		c.Multiply()
+	case INPUT:
+		c.Input()
+		stop = c.inputClosed || c.waiting
+	case OUTPUT:
+		c.Output()
+	case JMP_IF_TRUE:
+		c.JumpIfTrue()
+	case JMP_IF_FALSE:
+		c.JumpIfFalse()
+	case LESS_THAN:
+		c.LessThan()
+	case EQUALS:
+		c.OpEquals()
+	case ADJ_RELATIVE_BASE:
+		c.OpAdjustRelativeBase()
+	case HALT:
+		stop = true
+	default:
+		panic(fmt.Sprintf("instruction %d does not exist at address %d", instruction, c.ip))
+	}
+	if !c.waiting && !c.inputClosed {
+		if c.tracers != nil {
+			c.trace(event)
+		}
+		c.steps++
+	}
+	return stop
+}
+
+// int converts a word used as an address, relative base adjustment or addressing mode. A word that does not fit an
+// int faults with an OverflowError.
+func (c *Machine[W, A]) int(w W) int {
+	v, ok := c.words.ToInt(w)
+	if !ok {
+		panic(&OverflowError{Op: "as", A: fmt.Sprint(w), B: "int", Word: fmt.Sprintf("%T", w)})
+	}
+	return v
+}
+
+// ints converts words to ints, see int
+func (c *Machine[W, A]) ints(words []W) []int {
+	if words == nil {
+		return nil
+	}
+	values := make([]int, len(words))
+	for i, w := range words {
+		values[i] = c.int(w)
+	}
+	return values
+}
+
+// bool converts a comparison result to a word
+func (c *Machine[W, A]) bool(b bool) W {
+	if b {
+		return c.words.FromInt(1)
+	}
+	return c.words.FromInt(0)
+}
+
+func (c *Machine[W, A]) arg(pos int) *W {
+	mode := c.int(*c.cell(c.ip)) / 100
+	for i := 0; i < pos; i++ {
+		mode = mode / 10
+	}
+	mode = mode % 10
+
+	switch mode {
+	case IMMEDIATE_MODE:
+		return c.cell(c.ip + 1 + pos)
+	case POSITION_MODE:
+		return c.cell(c.int(*c.cell(c.ip + 1 + pos)))
+	case RELATIVE_MODE:
+		return c.cell(c.relativeBase + c.int(*c.cell(c.ip + 1 + pos)))
+	default:
+		panic("unknonwn addressing mode")
+	}
+}
+
+// Add (opcode=1) the first two arguments and store into the third. The first two argument addressing modes support POSITION and IMMEDIATE.
+func (c *Machine[W, A]) Add() {
+	arg1 := c.arg(0)
+	arg2 := c.arg(1)
+	out := c.arg(2)
+	*out = c.words.Add(*arg1, *arg2)
+	c.ip += 4
+}
+
+// Multiply (opcode=2) the first two arguments and store into the third. The first two argument addressing modes support POSITION and IMMEDIATE.
+func (c *Machine[W, A]) Multiply() {
+	arg1 := c.arg(0)
+	arg2 := c.arg(1)
+	out := c.arg(2)
+	*out = c.words.Mul(*arg1, *arg2)
+	c.ip += 4
+}
+
+// Input (opcode=3) takes a single integer from input and saves it to the position given by its (only) argument.
+// A closed or pending input leaves the instruction pointer on the INPUT instruction.
+func (c *Machine[W, A]) Input() {
+	out := c.arg(0)
+	val, status := c.input(c.policy == InputBlock)
+	if status == inputPending && c.policy == InputDefault {
+		val, status = c.words.FromInt(c.defaultInput), inputReady
+		c.defaultsServed++
+		c.consecutiveDefaults++
+	} else if status == inputReady {
+		c.consecutiveDefaults = 0
+	}
+
+	c.waiting = status == inputPending
+	switch status {
+	case inputEOF:
+		c.logs.io.Debug("input closed", zap.Int("instructionPointer", c.ip))
+		c.inputClosed = true
+		return
+	case inputPending:
+		c.logs.io.Debug("waiting for input", zap.Int("instructionPointer", c.ip))
+		return
+	}
+	if ce := c.logs.io.Check(zap.DebugLevel, "input value"); ce != nil {
+		ce.Write(zap.Int("instructionPointer", c.ip), zap.Any("input", val))
+	}
+	*out = val
+	c.ip += 2
+}
+
+// Output (opcode=4) gets its argument and writes it to the output. The argument supports addressing modes POSITION and IMMEDIATE.
+func (c *Machine[W, A]) Output() {
+	arg := c.arg(0)
+	if c.limits.MaxOutputs > 0 && c.outputs >= c.limits.MaxOutputs {
+		c.exceed(LimitOutputs, 0, nil)
+	}
+	if ce := c.logs.io.Check(zap.DebugLevel, "output value"); ce != nil {
+		ce.Write(zap.Int("instructionPointer", c.ip), zap.Any("output", *arg))
+	}
+	c.output(*arg)
+	c.outputs++
+	c.consecutiveDefaults = 0
+	c.ip += 2
+}
+
+// JumpIfTrue (opcode=5): if the first argument is non-zero, then set the instruction pointer to the value from the second argument. Otherwise do nothing. The arguments support addressing modes POSITION and IMMEDIATE.
+func (c *Machine[W, A]) JumpIfTrue() {
+	arg1 := c.arg(0)
+	if !c.words.IsZero(*arg1) {
+		arg2 := c.arg(1)
+		c.ip = c.int(*arg2)
+	} else {
+		c.ip += 3
+	}
+}
+
+// JumpIfFalse (opcode=6): if the first argument is zero, then set the instruction pointer to the value from the second argument. Otherwise do nothing. The arguments support addressing modes POSITION and IMMEDIATE.
+func (c *Machine[W, A]) JumpIfFalse() {
+	arg1 := c.arg(0)
+	if c.words.IsZero(*arg1) {
+		arg2 := c.arg(1)
+		c.ip = c.int(*arg2)
+	} else {
+		c.ip += 3
+	}
+}
+
+// LessThan (opcode=7) takes two arguments and if arg1 is less than arg2 write 1 into the third location of the third argument, otherwise write 0. The first two argument addressing modes support POSITION and IMMEDIATE.
+func (c *Machine[W, A]) LessThan() {
+	arg1 := c.arg(0)
+	arg2 := c.arg(1)
+	out := c.arg(2)
+	*out = c.bool(c.words.Less(*arg1, *arg2))
+
+	c.ip += 4
+}
+
+// OpEquals (opcode=8) takes two arguments and if arg1 equals arg2 write 1 into the third location of the third argument, otherwise write 0. The first two argument addressing modes support POSITION and IMMEDIATE.
+func (c *Machine[W, A]) OpEquals() {
+	arg1 := c.arg(0)
+	arg2 := c.arg(1)
+	out := c.arg(2)
+	*out = c.bool(c.words.Equal(*arg1, *arg2))
+
+	c.ip += 4
+}
+
+// OpAdjustRelativeBase (opcode=9) takes an adjustment to the relative base. The first two argument addressing modes support POSITION and IMMEDIATE.
+func (c *Machine[W, A]) OpAdjustRelativeBase() {
+	arg := c.arg(0)
+	c.relativeBase += c.int(*arg)
+	if c.relativeBase > c.maxRelativeBase {
+		c.maxRelativeBase = c.relativeBase
+	}
+	c.ip += 2
+}
@@ -0,0 +1,80 @@
+package intcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInputPolicies(t *testing.T) {
+	// reads two values and outputs their sum
+	program := []int{3, 11, 3, 12, 1, 11, 12, 13, 4, 13, 99, 0, 0, 0}
+	tests := []struct {
+		name        string
+		opts        []Option
+		send        []int
+		want        HaltReason
+		outputs     []int
+		defaults    int
+		consecutive int
+	}{
+		{"block suspends without queued input", nil, []int{1}, HaltWaiting, nil, 0, 0},
+		{"suspend", []Option{WithInputPolicy(InputSuspend)}, nil, HaltWaiting, nil, 0, 0},
+		{"both queued", []Option{WithInputPolicy(InputSuspend)}, []int{1, 2}, HaltOpcode, []int{3}, 0, 0},
+		{"default", []Option{WithDefaultInput(-1)}, nil, HaltOpcode, []int{-2}, 2, 0},
+		{"default after input", []Option{WithDefaultInput(5)}, []int{1}, HaltOpcode, []int{6}, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComputer(program, tt.opts...)
+			c.Send(tt.send...)
+			result, err := c.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Reason != tt.want {
+				t.Errorf("stopped with %v, want %v", result.Reason, tt.want)
+			}
+			if out := c.TakeOutput(); !reflect.DeepEqual(out, tt.outputs) {
+				t.Errorf("outputs %v, want %v", out, tt.outputs)
+			}
+			if c.DefaultsServed() != tt.defaults || c.ConsecutiveDefaults() != tt.consecutive {
+				t.Errorf("%d defaults, %d consecutive, want %d and %d", c.DefaultsServed(), c.ConsecutiveDefaults(),
+					tt.defaults, tt.consecutive)
+			}
+		})
+	}
+}
+
+func TestConsecutiveDefaults(t *testing.T) {
+	// polls three times, then outputs and polls once more
+	c := NewComputer([]int{3, 11, 3, 11, 3, 11, 4, 11, 3, 11, 99, 0}, WithDefaultInput(-1))
+	for i, want := range []int{1, 2, 3, 0, 1} {
+		c.E()
+		if c.ConsecutiveDefaults() != want {
+			t.Errorf("after instruction %d: %d consecutive defaults, want %d", i, c.ConsecutiveDefaults(), want)
+		}
+	}
+	if c.DefaultsServed() != 4 {
+		t.Errorf("%d defaults served, want 4", c.DefaultsServed())
+	}
+}
+
+func TestSuspendedInputResumes(t *testing.T) {
+	c := NewComputer([]int{3, 5, 4, 5, 99, 0})
+	if result, _ := c.Run(); result.Reason != HaltWaiting || c.IP() != 0 {
+		t.Fatalf("stopped with %v at %d, want waiting at 0", result.Reason, c.IP())
+	}
+	c.Send(42)
+	if result, _ := c.Run(); result.Reason != HaltOpcode {
+		t.Fatalf("stopped with %v, want halt", result.Reason)
+	}
+	if out := c.TakeOutput(); !reflect.DeepEqual(out, []int{42}) {
+		t.Errorf("outputs %v, want [42]", out)
+	}
+
+	c = NewComputer([]int{3, 5, 4, 5, 99, 0})
+	c.CloseInput()
+	if result, _ := c.Run(); result.Reason != HaltInputEOF || !c.InputClosed() {
+		t.Errorf("stopped with %v, want input EOF", result.Reason)
+	}
+}
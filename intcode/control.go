@@ -0,0 +1,159 @@
+package intcode
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// control lets other goroutines pause and inspect a running machine. Until one of them does, the machine runs without
+// the lock. From then on it holds the lock while it executes an instruction and releases it while it blocks on a
+// channel, so Pause and State wait at most for one instruction.
+type control struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	// requests counts the goroutines waiting for the lock, the machine lets them go first
+	requests int32
+	paused   bool
+	// blocked is set while the machine waits for a value on its input channel
+	blocked bool
+
+	// supervised is set by the first Pause, Resume or State, unlocked while the machine executes without the lock
+	supervised int32
+	unlocked   int32
+	// held is set while the machine holds the lock, only the machine goroutine uses it
+	held bool
+	// done is the Done channel of the context of the limits, interrupted is set when it ended a wait
+	done        <-chan struct{}
+	interrupted bool
+}
+
+func (ct *control) init(done <-chan struct{}) {
+	ct.cond = sync.NewCond(&ct.mu)
+	ct.done = done
+}
+
+// enter starts executing on the machine goroutine and waits while the machine is paused
+func (ct *control) enter() {
+	ct.lock()
+	ct.wait()
+}
+
+// lock takes the lock for the machine goroutine once the machine is supervised
+func (ct *control) lock() {
+	if atomic.LoadInt32(&ct.supervised) == 0 {
+		atomic.StoreInt32(&ct.unlocked, 1)
+		// acquire sets supervised before it looks at unlocked, so one of the two sees the other
+		if atomic.LoadInt32(&ct.supervised) == 0 {
+			return
+		}
+		atomic.StoreInt32(&ct.unlocked, 0)
+	}
+	ct.mu.Lock()
+	ct.held = true
+}
+
+// leave ends what enter or lock started
+func (ct *control) leave() {
+	if ct.held {
+		ct.held = false
+		ct.mu.Unlock()
+		return
+	}
+	atomic.StoreInt32(&ct.unlocked, 0)
+}
+
+// acquire takes the lock ahead of the machine, once an instruction that runs without the lock finished
+func (ct *control) acquire() {
+	atomic.StoreInt32(&ct.supervised, 1)
+	atomic.AddInt32(&ct.requests, 1)
+	ct.mu.Lock()
+	atomic.AddInt32(&ct.requests, -1)
+	for atomic.LoadInt32(&ct.unlocked) != 0 {
+		runtime.Gosched()
+	}
+}
+
+// release returns the lock and wakes the machine
+func (ct *control) release() {
+	ct.cond.Broadcast()
+	ct.mu.Unlock()
+}
+
+// wait holds the machine, if it has the lock, while it is paused or others want the lock. The end of the context
+// ends the wait too, so the limit can stop a paused machine.
+func (ct *control) wait() {
+	if !ct.held || !ct.mustWait() {
+		return
+	}
+	if ct.done != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			select {
+			case <-ct.done:
+				ct.mu.Lock()
+				ct.cond.Broadcast()
+				ct.mu.Unlock()
+			case <-stop:
+			}
+		}()
+	}
+	for ct.mustWait() {
+		select {
+		case <-ct.done:
+			ct.interrupted = true
+			return
+		default:
+		}
+		ct.cond.Wait()
+	}
+}
+
+// mustWait tells if the machine is paused or others want the lock
+func (ct *control) mustWait() bool {
+	return ct.paused || atomic.LoadInt32(&ct.requests) > 0
+}
+
+// LiveState is a copy of the registers and memory of a machine, see Machine.State
+type LiveState[W any] struct {
+	IP           int
+	RelativeBase int
+	Steps        int
+	// BlockedOnInput is set while an INPUT instruction waits for a value, on its channel or suspended
+	BlockedOnInput bool
+	Paused         bool
+	Memory         []W
+}
+
+// Pause stops the machine before its next instruction and returns once it stopped, or waits on its input or output
+// channel. A value that arrives on the input while the machine is paused is read but not stored until Resume.
+// Pausing a machine that is not running stops it when it runs. Pause, Resume and State may be called from any
+// goroutine, but not from a Tracer of the machine itself.
+func (c *Machine[W, A]) Pause() {
+	c.ctl.acquire()
+	c.ctl.paused = true
+	c.ctl.release()
+}
+
+// Resume continues a paused machine
+func (c *Machine[W, A]) Resume() {
+	c.ctl.acquire()
+	c.ctl.paused = false
+	c.ctl.release()
+}
+
+// State copies the registers and memory between two instructions. Unlike the other accessors it is safe to call
+// while the machine runs in another goroutine.
+func (c *Machine[W, A]) State() LiveState[W] {
+	c.ctl.acquire()
+	defer c.ctl.release()
+	return LiveState[W]{
+		IP:             c.ip,
+		RelativeBase:   c.relativeBase,
+		Steps:          c.steps,
+		BlockedOnInput: c.ctl.blocked || c.waiting,
+		Paused:         c.ctl.paused,
+		Memory:         append([]W(nil), c.memory...),
+	}
+}
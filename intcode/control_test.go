@@ -0,0 +1,140 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// counter counts to 100000 and outputs the count
+const counter = `loop:   add  [n], 1, [n]
+        lt   [n], 100000, [c]
+        jt   [c], loop
+        out  [n]
+        halt
+n:      data 0
+c:      data 0
+`
+
+func TestPauseResume(t *testing.T) {
+	program, _, err := Assemble(strings.NewReader(counter), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, output := make(chan int), make(chan int, 1)
+	c := MakeComputer(program, input, output)
+	// a machine paused before it runs stops when it runs
+	c.Pause()
+	go c.Run()
+
+	first := c.State()
+	time.Sleep(10 * time.Millisecond)
+	second := c.State()
+	if !first.Paused || first.Steps != second.Steps || first.IP != second.IP {
+		t.Fatalf("paused machine moved from %+v to %+v", first, second)
+	}
+
+	c.Resume()
+	select {
+	case val := <-output:
+		if val != 100000 {
+			t.Errorf("output %d, want 100000", val)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the resumed machine did not finish")
+	}
+
+	// pausing in the middle of a run stops between two instructions
+	c = MakeComputer(program, input, output)
+	go c.Run()
+	time.Sleep(time.Millisecond)
+	c.Pause()
+	state := c.State()
+	if n := state.Memory[len(program)-2]; n < 100000 && state.Steps != 3*n-2 && state.Steps != 3*n-1 && state.Steps != 3*n {
+		t.Errorf("paused after %d steps with the count at %d", state.Steps, n)
+	}
+	c.Resume()
+	<-output
+}
+
+func TestStateWhileBlocked(t *testing.T) {
+	input, output := make(chan int), make(chan int, 1)
+	c := MakeComputer([]int{3, 9, 1002, 9, 3, 9, 4, 9, 99, 0}, input, output)
+	done := make(chan RunResult)
+	go func() {
+		result, _ := c.Run()
+		done <- result
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !c.State().BlockedOnInput {
+		if time.Now().After(deadline) {
+			t.Fatalf("the machine never waited for input")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if state := c.State(); state.IP != 0 || state.Steps != 0 {
+		t.Errorf("blocked at %d after %d steps", state.IP, state.Steps)
+	}
+
+	input <- 14
+	if val := <-output; val != 42 {
+		t.Errorf("output %d, want 42", val)
+	}
+	if result := <-done; result.Reason != HaltOpcode || result.Steps != 4 {
+		t.Errorf("result %v", result)
+	}
+	if state := c.State(); state.BlockedOnInput || state.Memory[9] != 42 {
+		t.Errorf("state after the run %+v", state)
+	}
+}
+
+func TestPausedContext(t *testing.T) {
+	tests := []struct {
+		name string
+		// early pauses the machine before it runs
+		early bool
+	}{
+		{"paused before the run", true},
+		{"paused while running", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			c := MakeComputer([]int{1105, 1, 0}, nil, nil, WithLimits(Limits{Context: ctx}))
+			if tt.early {
+				c.Pause()
+			}
+			type run struct {
+				result RunResult
+				err    error
+			}
+			done := make(chan run)
+			go func() {
+				result, err := c.Run()
+				done <- run{result, err}
+			}()
+			if !tt.early {
+				time.Sleep(time.Millisecond)
+				c.Pause()
+			}
+
+			// only the context can stop the paused machine
+			cancel()
+			select {
+			case r := <-done:
+				if r.result.Reason != HaltLimit || !errors.Is(r.err, context.Canceled) {
+					t.Errorf("stopped with %v: %v", r.result.Reason, r.err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("the paused machine ignored the cancelled context")
+			}
+			if state := c.State(); !state.Paused {
+				t.Errorf("state %+v is no longer paused", state)
+			}
+		})
+	}
+}
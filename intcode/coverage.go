@@ -0,0 +1,264 @@
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// Coverage counts the executions of every instruction and the directions every conditional jump took. It is a
+// Tracer, so several runs can share one, and the coverage of separate runs adds up with Merge.
+type Coverage struct {
+	// Executed counts the executions of the instruction at each address
+	Executed map[int]int `json:"executed"`
+	// Branches counts the directions of the conditional jump at each address
+	Branches map[int]*Branch `json:"branches"`
+}
+
+// Branch counts how often a conditional jump was taken and not taken
+type Branch struct {
+	Taken    int `json:"taken"`
+	NotTaken int `json:"notTaken"`
+}
+
+// NewCoverage creates an empty coverage
+func NewCoverage() *Coverage {
+	return &Coverage{Executed: make(map[int]int), Branches: make(map[int]*Branch)}
+}
+
+// Trace records an executed instruction
+func (cov *Coverage) Trace(c Inspector, e Event) {
+	cov.Executed[e.IP]++
+	if e.Opcode != JMP_IF_TRUE && e.Opcode != JMP_IF_FALSE {
+		return
+	}
+	b := cov.branch(e.IP)
+	if e.Jumped {
+		b.Taken++
+	} else {
+		b.NotTaken++
+	}
+}
+
+func (cov *Coverage) branch(address int) *Branch {
+	b, ok := cov.Branches[address]
+	if !ok {
+		b = &Branch{}
+		cov.Branches[address] = b
+	}
+	return b
+}
+
+// Merge adds the counts of other
+func (cov *Coverage) Merge(other *Coverage) {
+	for address, count := range other.Executed {
+		cov.Executed[address] += count
+	}
+	for address, ob := range other.Branches {
+		b := cov.branch(address)
+		b.Taken += ob.Taken
+		b.NotTaken += ob.NotTaken
+	}
+}
+
+// CoverageSummary counts the covered instructions and jump directions of a program
+type CoverageSummary struct {
+	Instructions int
+	Covered      int
+	// Directions counts both directions of every conditional jump, a jump with an immediate condition has one
+	Directions        int
+	CoveredDirections int
+}
+
+func (s CoverageSummary) String() string {
+	return fmt.Sprintf("instructions %d/%d (%s), jump directions %d/%d (%s)",
+		s.Covered, s.Instructions, percent(s.Covered, s.Instructions),
+		s.CoveredDirections, s.Directions, percent(s.CoveredDirections, s.Directions))
+}
+
+func percent(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(total))
+}
+
+// coverageLine is a line of the disassembly annotated with coverage
+type coverageLine struct {
+	Address int
+	Text    string
+	// Code is set for instructions reachable from the start of the program or executed
+	Code   bool
+	Count  int
+	Branch *Branch
+	// Directions is the number of directions the jump can take, zero for other instructions
+	Directions int
+}
+
+// Class names the coverage of the line: data, missed, partial or covered
+func (l coverageLine) Class() string {
+	switch {
+	case !l.Code:
+		return "data"
+	case l.Count == 0:
+		return "missed"
+	case l.Directions > l.covered():
+		return "partial"
+	default:
+		return "covered"
+	}
+}
+
+// covered returns the number of jump directions taken
+func (l coverageLine) covered() int {
+	n := 0
+	if l.Branch != nil && l.Branch.Taken > 0 {
+		n++
+	}
+	if l.Branch != nil && l.Branch.NotTaken > 0 {
+		n++
+	}
+	if n > l.Directions {
+		n = l.Directions
+	}
+	return n
+}
+
+// BranchText describes the jump directions taken, empty for other instructions
+func (l coverageLine) BranchText() string {
+	if l.Directions == 0 {
+		return ""
+	}
+	b := l.Branch
+	if b == nil {
+		b = &Branch{}
+	}
+	return fmt.Sprintf("T:%d F:%d", b.Taken, b.NotTaken)
+}
+
+// CountText is the execution count, "-" for instructions that never ran and empty for data
+func (l coverageLine) CountText() string {
+	switch {
+	case !l.Code:
+		return ""
+	case l.Count == 0:
+		return "-"
+	default:
+		return fmt.Sprint(l.Count)
+	}
+}
+
+// lines disassembles the program with a line for every instruction that is reachable or ran. The listing is
+// decoded like Disassemble, except that an instruction that overlaps a later executed one is cut short into data.
+func (cov *Coverage) lines(program []int) ([]coverageLine, CoverageSummary) {
+	reachable := translatable(program)
+	isCode := func(address int) bool {
+		_, ok := reachable[address]
+		return ok || cov.Executed[address] > 0
+	}
+
+	var lines []coverageLine
+	var summary CoverageSummary
+	for address := 0; address < len(program); {
+		inst, err := Decode(program, address)
+		if err == nil {
+			for i := 1; i < inst.Len(); i++ {
+				if cov.Executed[address+i] > 0 {
+					err = fmt.Errorf("overlaps the instruction at %d", address+i)
+				}
+			}
+		}
+		if err != nil {
+			// a self-modifying program may run what the original program has as data
+			l := coverageLine{Address: address, Text: fmt.Sprintf("data %d", program[address]), Count: cov.Executed[address]}
+			if l.Count > 0 {
+				l.Code = true
+				summary.Instructions++
+				summary.Covered++
+			}
+			lines = append(lines, l)
+			address++
+			continue
+		}
+
+		l := coverageLine{Address: address, Text: inst.String(), Code: isCode(address), Count: cov.Executed[address]}
+		if l.Code && (inst.Opcode == JMP_IF_TRUE || inst.Opcode == JMP_IF_FALSE) {
+			l.Branch = cov.Branches[address]
+			l.Directions = 2
+			if inst.Modes[0] == IMMEDIATE_MODE {
+				l.Directions = 1
+			}
+		}
+		if l.Code {
+			summary.Instructions++
+			if l.Count > 0 {
+				summary.Covered++
+			}
+			summary.Directions += l.Directions
+			summary.CoveredDirections += l.covered()
+		}
+		lines = append(lines, l)
+		address += inst.Len()
+	}
+	return lines, summary
+}
+
+// Summary counts the covered instructions and jump directions of the program
+func (cov *Coverage) Summary(program []int) CoverageSummary {
+	_, summary := cov.lines(program)
+	return summary
+}
+
+// WriteText writes the disassembly of the program with the execution count and the taken (T) and not taken (F)
+// counts of jumps in front of every instruction. Instructions that never ran show "-", jumps that did not take both
+// directions are marked with "!".
+func (cov *Coverage) WriteText(w io.Writer, program []int) error {
+	lines, summary := cov.lines(program)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s\n\n", summary)
+	for _, l := range lines {
+		mark := " "
+		if l.Class() == "partial" {
+			mark = "!"
+		}
+		fmt.Fprintf(bw, "%10s %-16s%s %-32s ; %d\n", l.CountText(), l.BranchText(), mark, l.Text, l.Address)
+	}
+	return bw.Flush()
+}
+
+var coverageHTML = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>intcode coverage</title>
+<style>
+body { font-family: monospace; }
+table { border-collapse: collapse; }
+td { padding: 0 0.6em; white-space: pre; }
+td.count, td.address { text-align: right; color: #666; }
+tr.covered { background: #d8f5d8; }
+tr.partial { background: #f9f0c0; }
+tr.missed { background: #f8d0d0; }
+tr.data { color: #999; }
+</style>
+</head>
+<body>
+<p>{{.Summary}}</p>
+<table>
+<tr><th>address</th><th>count</th><th>jump</th><th>instruction</th></tr>
+{{range .Lines}}<tr class="{{.Class}}"><td class="address">{{.Address}}</td><td class="count">{{.CountText}}</td><td>{{.BranchText}}</td><td>{{.Text}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes the annotated disassembly of WriteText as an HTML page. Covered instructions are green, missed
+// ones red and jumps that did not take both directions yellow.
+func (cov *Coverage) WriteHTML(w io.Writer, program []int) error {
+	lines, summary := cov.lines(program)
+	return coverageHTML.Execute(w, struct {
+		Summary CoverageSummary
+		Lines   []coverageLine
+	}{summary, lines})
+}
@@ -0,0 +1,86 @@
+package intcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// runCovered runs the program with the input and records its coverage
+func runCovered(t *testing.T, program []int, input ...int) *Coverage {
+	t.Helper()
+	cov := NewCoverage()
+	c := NewComputer(program, WithTracer(cov))
+	c.Send(input...)
+	c.CloseInput()
+	if _, err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return cov
+}
+
+func TestCoverageSummary(t *testing.T) {
+	// compare8 has 15 reachable instructions, its 6 jumps have 8 directions as 4 of them have an immediate condition
+	tests := []struct {
+		name   string
+		inputs [][]int
+		want   CoverageSummary
+	}{
+		{"below", [][]int{{7}}, CoverageSummary{15, 8, 8, 3}},
+		{"equal", [][]int{{8}}, CoverageSummary{15, 7, 8, 2}},
+		{"above", [][]int{{9}}, CoverageSummary{15, 10, 8, 4}},
+		{"merged", [][]int{{7}, {8}, {9}}, CoverageSummary{15, 15, 8, 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cov := NewCoverage()
+			for _, input := range tt.inputs {
+				cov.Merge(runCovered(t, compare8, input...))
+			}
+			if got := cov.Summary(compare8); got != tt.want {
+				t.Errorf("summary %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoverageText(t *testing.T) {
+	cov := runCovered(t, compare8, 7)
+	var buf bytes.Buffer
+	if err := cov.WriteText(&buf, compare8); err != nil {
+		t.Fatal(err)
+	}
+	lines := make(map[string]string)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if i := strings.LastIndex(line, "; "); i >= 0 {
+			lines[line[i+2:]] = line
+		}
+	}
+	tests := []struct {
+		address string
+		want    []string
+	}{
+		// the jump on equality was only not taken
+		{"6", []string{"T:0 F:1", "!"}},
+		{"0", []string{"1 ", "in   [21]"}},
+		{"22", []string{" - ", "mul  [21], 125, [20]"}},
+		{"45", []string{"data 98"}},
+	}
+	for _, tt := range tests {
+		for _, want := range tt.want {
+			if !strings.Contains(lines[tt.address], want) {
+				t.Errorf("line of address %s %q does not contain %q", tt.address, lines[tt.address], want)
+			}
+		}
+	}
+
+	buf.Reset()
+	if err := cov.WriteHTML(&buf, compare8); err != nil {
+		t.Fatal(err)
+	}
+	for _, class := range []string{`class="covered"`, `class="partial"`, `class="missed"`, `class="data"`} {
+		if !strings.Contains(buf.String(), class) {
+			t.Errorf("html has no %s line", class)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package intcode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+)
+
+// The binary format is a header, a payload of zig-zag varints and a checksum:
+//
+//	magic "ICOD" | version byte | kind byte | payload | CRC-32 (IEEE, big endian) of everything before it
+//
+// A program payload is the value count followed by the values. A state payload is the instruction pointer,
+// the relative base, a flags byte, the memory size, the used memory length (trailing zeros are not stored), the used
+// memory, then the queued input and the queued output, each as a count followed by the values.
+const (
+	binaryMagic   = "ICOD"
+	binaryVersion = 1
+
+	kindProgram = 'P'
+	kindState   = 'S'
+
+	stateInputClosed = 1 << 0
+	stateWaiting     = 1 << 1
+)
+
+var (
+	// ErrNotBinary means the data does not start with the binary format magic
+	ErrNotBinary = errors.New("not a binary intcode file")
+	// ErrChecksum means the data was corrupted
+	ErrChecksum = errors.New("binary intcode checksum mismatch")
+)
+
+// State is a complete copy of a computer. The queues are only filled for computers created with NewComputer.
+type State struct {
+	IP           int   `json:"ip"`
+	RelativeBase int   `json:"relativeBase"`
+	Memory       []int `json:"memory"`
+	Input        []int `json:"input,omitempty"`
+	Output       []int `json:"output,omitempty"`
+	InputClosed  bool  `json:"inputClosed,omitempty"`
+	Waiting      bool  `json:"waiting,omitempty"`
+}
+
+// Snapshot copies the complete state of the computer. It panics with an OverflowError for a word that does not fit an
+// int.
+func (c *Machine[W, A]) Snapshot() *State {
+	return &State{
+		IP:           c.ip,
+		RelativeBase: c.relativeBase,
+		Memory:       c.ints(c.memory),
+		Input:        c.ints(c.inQueue),
+		Output:       c.ints(c.outQueue),
+		InputClosed:  c.inClosed || c.inputClosed,
+		Waiting:      c.waiting,
+	}
+}
+
+// Restore creates a computer with queues, see NewComputer, that continues from the state
+func Restore(s *State, opts ...Option) *Computer {
+	c := NewComputer(nil, opts...)
+	c.memory = append([]int(nil), s.Memory...)
+	c.programLen = len(c.memory)
+	c.ip = s.IP
+	c.relativeBase = s.RelativeBase
+	c.maxRelativeBase = s.RelativeBase
+	c.inQueue = append([]int(nil), s.Input...)
+	c.outQueue = append([]int(nil), s.Output...)
+	c.inClosed = s.InputClosed
+	c.waiting = s.Waiting
+	return c
+}
+
+// IsBinary reports whether the data starts with the binary format magic
+func IsBinary(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(binaryMagic))
+}
+
+// EncodeProgram writes the program in the binary format
+func EncodeProgram(w io.Writer, program []int) error {
+	e := newEncoder(kindProgram)
+	e.values(program)
+	return e.finish(w)
+}
+
+// EncodeState writes the computer state in the binary format
+func EncodeState(w io.Writer, s *State) error {
+	e := newEncoder(kindState)
+	e.int(s.IP)
+	e.int(s.RelativeBase)
+	flags := 0
+	if s.InputClosed {
+		flags |= stateInputClosed
+	}
+	if s.Waiting {
+		flags |= stateWaiting
+	}
+	e.buf.WriteByte(byte(flags))
+
+	used := len(s.Memory)
+	for used > 0 && s.Memory[used-1] == 0 {
+		used--
+	}
+	e.int(len(s.Memory))
+	e.values(s.Memory[:used])
+	e.values(s.Input)
+	e.values(s.Output)
+	return e.finish(w)
+}
+
+// DecodeProgram reads a program written by EncodeProgram
+func DecodeProgram(r io.Reader) ([]int, error) {
+	d, err := newDecoder(r, kindProgram)
+	if err != nil {
+		return nil, err
+	}
+	program := d.values()
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return program, nil
+}
+
+// DecodeState reads a computer state written by EncodeState
+func DecodeState(r io.Reader) (*State, error) {
+	d, err := newDecoder(r, kindState)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &State{}
+	s.IP = d.int()
+	s.RelativeBase = d.int()
+	flags := d.byte()
+	s.InputClosed = flags&stateInputClosed != 0
+	s.Waiting = flags&stateWaiting != 0
+	size := d.int()
+	used := d.values()
+	if d.err == nil && (size < len(used) || size > maxDecodedLength) {
+		d.err = fmt.Errorf("invalid memory size %d", size)
+	}
+	if d.err == nil {
+		s.Memory = make([]int, size)
+		copy(s.Memory, used)
+	}
+	s.Input = d.values()
+	s.Output = d.values()
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// BinaryKind returns the kind of a binary file: 'P' for programs and 'S' for states
+func BinaryKind(data []byte) (byte, error) {
+	if !IsBinary(data) || len(data) < len(binaryMagic)+2 {
+		return 0, ErrNotBinary
+	}
+	return data[len(binaryMagic)+1], nil
+}
+
+type encoder struct {
+	buf bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func newEncoder(kind byte) *encoder {
+	e := &encoder{}
+	e.buf.WriteString(binaryMagic)
+	e.buf.WriteByte(binaryVersion)
+	e.buf.WriteByte(kind)
+	return e
+}
+
+// int writes a zig-zag varint
+func (e *encoder) int(val int) {
+	n := binary.PutVarint(e.tmp[:], int64(val))
+	e.buf.Write(e.tmp[:n])
+}
+
+func (e *encoder) values(vals []int) {
+	e.int(len(vals))
+	for _, val := range vals {
+		e.int(val)
+	}
+}
+
+func (e *encoder) finish(w io.Writer) error {
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE(e.buf.Bytes()))
+	e.buf.Write(sum[:])
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+// maxDecodedLength guards against allocating huge slices for corrupted lengths
+const maxDecodedLength = 1 << 28
+
+type decoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func newDecoder(r io.Reader, kind byte) (*decoder, error) {
+	data, err := ioutil.ReadAll(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	dataKind, err := BinaryKind(data)
+	if err != nil {
+		return nil, err
+	}
+	if version := data[len(binaryMagic)]; version != binaryVersion {
+		return nil, fmt.Errorf("unsupported binary intcode version %d", version)
+	}
+	if dataKind != kind {
+		return nil, fmt.Errorf("binary intcode kind %q, want %q", dataKind, kind)
+	}
+	if len(data) < len(binaryMagic)+2+4 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(sum) {
+		return nil, ErrChecksum
+	}
+	return &decoder{r: bytes.NewReader(body[len(binaryMagic)+2:])}, nil
+}
+
+func (d *decoder) int() int {
+	if d.err != nil {
+		return 0
+	}
+	val, err := binary.ReadVarint(d.r)
+	if err != nil {
+		d.err = fmt.Errorf("truncated binary intcode: %v", err)
+	}
+	return int(val)
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	b, err := d.r.ReadByte()
+	if err != nil {
+		d.err = fmt.Errorf("truncated binary intcode: %v", err)
+	}
+	return b
+}
+
+func (d *decoder) values() []int {
+	n := d.int()
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > maxDecodedLength || n > d.r.Len() {
+		d.err = fmt.Errorf("invalid value count %d", n)
+		return nil
+	}
+	vals := make([]int, n)
+	for i := range vals {
+		vals[i] = d.int()
+	}
+	return vals
+}
+
+func (d *decoder) finish() error {
+	if d.err == nil && d.r.Len() > 0 {
+		d.err = fmt.Errorf("%d unexpected trailing bytes", d.r.Len())
+	}
+	return d.err
+}
@@ -0,0 +1,138 @@
+package intcode
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// quine is the day 9 example that outputs a copy of itself
+var quine = []int{109, 1, 204, -1, 1001, 100, 1, 100, 1008, 100, 16, 101, 1006, 101, 0, 99}
+
+func TestProgramRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+	}{
+		{"quine", quine},
+		{"large values", []int{104, 1125899906842624, 99, -1125899906842624}},
+		{"extremes", []int{math.MaxInt64, math.MinInt64, 0, -1, 1}},
+		{"single", []int{99}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeProgram(&buf, tt.program); err != nil {
+				t.Fatal(err)
+			}
+			if !IsBinary(buf.Bytes()) {
+				t.Fatalf("encoded program has no magic")
+			}
+			if kind, err := BinaryKind(buf.Bytes()); err != nil || kind != 'P' {
+				t.Errorf("kind %q %v, want 'P'", kind, err)
+			}
+
+			program, err := DecodeProgram(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(program, tt.program) {
+				t.Errorf("decoded %v, want %v", program, tt.program)
+			}
+			// the text loader detects the binary format
+			program, err = ReadProgram(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(program, tt.program) {
+				t.Errorf("read %v, want %v", program, tt.program)
+			}
+		})
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	// outputs its input doubled until the input closes
+	program := []int{3, 11, 1002, 11, 2, 12, 4, 12, 1105, 1, 0, 0, 0}
+
+	c := NewComputer(program)
+	c.Send(1, 2)
+	if result, _ := c.Run(); result.Reason != HaltWaiting {
+		t.Fatalf("stopped with %v, want waiting", result.Reason)
+	}
+	c.Send(7)
+	state := c.Snapshot()
+
+	var buf bytes.Buffer
+	if err := EncodeState(&buf, state); err != nil {
+		t.Fatal(err)
+	}
+	if kind, err := BinaryKind(buf.Bytes()); err != nil || kind != 'S' {
+		t.Errorf("kind %q %v, want 'S'", kind, err)
+	}
+	decoded, err := DecodeState(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, state) {
+		t.Fatalf("decoded %+v, want %+v", decoded, state)
+	}
+	if !decoded.Waiting || !reflect.DeepEqual(decoded.Input, []int{7}) || !reflect.DeepEqual(decoded.Output, []int{2, 4}) {
+		t.Errorf("decoded state waiting %v with input %v and output %v", decoded.Waiting, decoded.Input, decoded.Output)
+	}
+
+	// the restored computer continues where the original left off
+	restored := Restore(decoded)
+	restored.CloseInput()
+	if result, _ := restored.Run(); result.Reason != HaltInputEOF {
+		t.Fatalf("restored computer stopped with %v, want input EOF", result.Reason)
+	}
+	if out := restored.TakeOutput(); !reflect.DeepEqual(out, []int{2, 4, 14}) {
+		t.Errorf("restored computer output %v, want [2 4 14]", out)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var program bytes.Buffer
+	if err := EncodeProgram(&program, []int{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	valid := program.Bytes()
+	corrupt := func(f func(data []byte) []byte) []byte {
+		return f(append([]byte(nil), valid...))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("1,2,3"), ErrNotBinary},
+		{"empty", nil, ErrNotBinary},
+		{"flipped bit", corrupt(func(data []byte) []byte {
+			data[len(binaryMagic)+3] ^= 1
+			return data
+		}), ErrChecksum},
+		{"truncated", valid[:len(valid)-1], ErrChecksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeProgram(bytes.NewReader(tt.data)); !errors.Is(err, tt.want) {
+				t.Errorf("error %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := DecodeState(bytes.NewReader(valid)); err == nil {
+		t.Errorf("decoded a program as a state")
+	}
+	version := corrupt(func(data []byte) []byte {
+		data[len(binaryMagic)] = binaryVersion + 1
+		return data
+	})
+	if _, err := DecodeProgram(bytes.NewReader(version)); err == nil {
+		t.Errorf("decoded an unknown version")
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/ljdelight/adventOfCode-2019/intcode
+
+go 1.18
+
+require (
+	go.uber.org/zap v1.13.0
+	gopkg.in/yaml.v2 v2.2.7
+)
+
+require (
+	go.uber.org/atomic v1.5.0 // indirect
+	go.uber.org/multierr v1.3.0 // indirect
+)
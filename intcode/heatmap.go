@@ -0,0 +1,169 @@
+package intcode
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// ErrEmptyProfile is returned when drawing a profile in which nothing was accessed
+var ErrEmptyProfile = errors.New("nothing was accessed")
+
+// Heatmap lays out the memory of a profile as a grid of Width cells per row, from address zero to the highest address
+// accessed, and colors every cell by its access counts on a logarithmic scale
+type Heatmap struct {
+	Profile *Profile
+	// Access is the kind of access cells are colored by, unless Combined is set
+	Access Access
+	// Combined colors writes red, executions green and reads blue, so self-modified code shows yellow and the
+	// stack, which is read and written, magenta
+	Combined bool
+	// Width is the number of cells per row
+	Width int
+}
+
+var (
+	// heatUnused is the color of cells that were never accessed
+	heatUnused = color.RGBA{R: 32, G: 32, B: 32, A: 255}
+	// heatStops are the colors of the single access scale, from the lowest count to the highest
+	heatStops = []color.RGBA{
+		{R: 40, G: 11, B: 84, A: 255},
+		{R: 187, G: 55, B: 84, A: 255},
+		{R: 249, G: 142, B: 9, A: 255},
+		{R: 252, G: 255, B: 164, A: 255},
+	}
+)
+
+// heat returns the position of a count on the logarithmic scale up to max, from 0 to 1
+func heat(count, max int) float64 {
+	if max <= 1 {
+		return 1
+	}
+	return math.Log1p(float64(count)) / math.Log1p(float64(max))
+}
+
+func maxCount(counts []int) int {
+	max := 0
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// colors returns the color of every cell
+func (h Heatmap) colors() []color.RGBA {
+	size := h.Profile.Size()
+	colors := make([]color.RGBA, size)
+	if h.Combined {
+		writes, executes, reads := h.Profile.Counts(AccessWrites), h.Profile.Counts(AccessExecutes), h.Profile.Counts(AccessReads)
+		maxWrites, maxExecutes, maxReads := maxCount(writes), maxCount(executes), maxCount(reads)
+		channel := func(count, max int) uint8 {
+			if count == 0 {
+				return 0
+			}
+			return uint8(128 + 127*heat(count, max))
+		}
+		for address := range colors {
+			if writes[address] == 0 && executes[address] == 0 && reads[address] == 0 {
+				colors[address] = heatUnused
+				continue
+			}
+			colors[address] = color.RGBA{
+				R: channel(writes[address], maxWrites),
+				G: channel(executes[address], maxExecutes),
+				B: channel(reads[address], maxReads),
+				A: 255,
+			}
+		}
+		return colors
+	}
+
+	counts := h.Profile.Counts(h.Access)
+	max := maxCount(counts)
+	for address, count := range counts {
+		if count == 0 {
+			colors[address] = heatUnused
+			continue
+		}
+		// interpolate between the two stops around the position on the scale
+		pos := heat(count, max) * float64(len(heatStops)-1)
+		i := int(pos)
+		if i >= len(heatStops)-1 {
+			colors[address] = heatStops[len(heatStops)-1]
+			continue
+		}
+		f := pos - float64(i)
+		from, to := heatStops[i], heatStops[i+1]
+		mix := func(a, b uint8) uint8 {
+			return uint8(float64(a) + f*(float64(b)-float64(a)))
+		}
+		colors[address] = color.RGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: 255}
+	}
+	return colors
+}
+
+// Legend describes what the colors mean and the highest count
+func (h Heatmap) Legend() string {
+	size := h.Profile.Size()
+	if h.Combined {
+		return fmt.Sprintf("%d cells, %d per row: red writes (max %d), green executes (max %d), blue reads (max %d)",
+			size, h.width(), maxCount(h.Profile.Writes), maxCount(h.Profile.Executes), maxCount(h.Profile.Reads))
+	}
+	return fmt.Sprintf("%d cells, %d per row: %s from 1 (dark) to %d (light) on a log scale",
+		size, h.width(), h.Access, maxCount(h.Profile.Counts(h.Access)))
+}
+
+func (h Heatmap) width() int {
+	if h.Width <= 0 {
+		return 32
+	}
+	return h.Width
+}
+
+// WritePNG writes the grid as a PNG image with scale pixels per cell. Cells beyond the highest address are transparent.
+// A profile in which nothing was accessed has no image and returns ErrEmptyProfile.
+func (h Heatmap) WritePNG(w io.Writer, scale int) error {
+	if scale <= 0 {
+		scale = 1
+	}
+	colors := h.colors()
+	if len(colors) == 0 {
+		return ErrEmptyProfile
+	}
+	width := h.width()
+	rows := (len(colors) + width - 1) / width
+	img := image.NewRGBA(image.Rect(0, 0, width*scale, rows*scale))
+	for address, c := range colors {
+		x, y := address%width*scale, address/width*scale
+		for dy := 0; dy < scale; dy++ {
+			for dx := 0; dx < scale; dx++ {
+				img.SetRGBA(x+dx, y+dy, c)
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// WriteANSI writes the grid for a terminal with 24-bit colors, two columns per cell and the first address of every
+// row in front of it
+func (h Heatmap) WriteANSI(w io.Writer) error {
+	colors := h.colors()
+	width := h.width()
+	bw := bufio.NewWriter(w)
+	for row := 0; row < len(colors); row += width {
+		fmt.Fprintf(bw, "%6d ", row)
+		for address := row; address < row+width && address < len(colors); address++ {
+			c := colors[address]
+			fmt.Fprintf(bw, "\x1b[48;2;%d;%d;%dm  ", c.R, c.G, c.B)
+		}
+		fmt.Fprintf(bw, "\x1b[0m\n")
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,120 @@
+package intcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// loop counts a data cell to 3 and halts
+var loop = []int{1001, 12, 1, 12, 1007, 12, 3, 13, 1005, 13, 0, 99, 0, 0}
+
+func TestHeatmapPNG(t *testing.T) {
+	tests := []struct {
+		name    string
+		heatmap Heatmap
+		scale   int
+		// bounds is the size of the image
+		bounds image.Point
+		// pixels are the colors at some points
+		pixels map[image.Point]color.RGBA
+	}{
+		{"executes", Heatmap{Access: AccessExecutes, Width: 4}, 2, image.Pt(8, 8), map[image.Point]color.RGBA{
+			{0, 0}: heatStops[len(heatStops)-1],
+			{0, 7}: heatUnused,
+			{5, 7}: {},
+		}},
+		{"reads", Heatmap{Access: AccessReads, Width: 14}, 1, image.Pt(14, 1), map[image.Point]color.RGBA{
+			{0, 0}:  heatUnused,
+			{12, 0}: heatStops[len(heatStops)-1],
+		}},
+		{"combined", Heatmap{Combined: true, Width: 7}, 0, image.Pt(7, 2), map[image.Point]color.RGBA{
+			{0, 0}: {R: 0, G: 255, B: 0, A: 255},
+			{5, 1}: {R: 255, G: 0, B: 255, A: 255},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.heatmap.Profile = profiled(t, loop)
+			var out bytes.Buffer
+			if err := tt.heatmap.WritePNG(&out, tt.scale); err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(&out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size := img.Bounds().Size(); size != tt.bounds {
+				t.Errorf("image is %v, want %v", size, tt.bounds)
+			}
+			for pt, want := range tt.pixels {
+				if got := color.RGBAModel.Convert(img.At(pt.X, pt.Y)).(color.RGBA); got != want {
+					t.Errorf("pixel %v is %v, want %v", pt, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHeatmapEmpty(t *testing.T) {
+	h := Heatmap{Profile: NewProfile()}
+	if err := h.WritePNG(&bytes.Buffer{}, 8); !errors.Is(err, ErrEmptyProfile) {
+		t.Errorf("drawing an empty profile returned %v", err)
+	}
+	var out bytes.Buffer
+	if err := h.WriteANSI(&out); err != nil || out.Len() != 0 {
+		t.Errorf("terminal view of an empty profile %q, %v", out.String(), err)
+	}
+}
+
+func TestHeatmapANSI(t *testing.T) {
+	tests := []struct {
+		width int
+		// rows are the first addresses of the rows
+		rows []string
+	}{
+		{4, []string{"     0 ", "     4 ", "     8 ", "    12 "}},
+		{14, []string{"     0 "}},
+		{0, []string{"     0 "}},
+	}
+	for _, tt := range tests {
+		h := Heatmap{Profile: profiled(t, loop), Access: AccessExecutes, Width: tt.width}
+		var out bytes.Buffer
+		if err := h.WriteANSI(&out); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if len(lines) != len(tt.rows) {
+			t.Fatalf("width %d: %d rows, want %d", tt.width, len(lines), len(tt.rows))
+		}
+		for i, line := range lines {
+			if !strings.HasPrefix(line, tt.rows[i]) || !strings.HasSuffix(line, "\x1b[0m") {
+				t.Errorf("width %d: row %q", tt.width, line)
+			}
+		}
+	}
+}
+
+func TestHeatmapLegend(t *testing.T) {
+	tests := []struct {
+		heatmap Heatmap
+		want    string
+	}{
+		{Heatmap{Access: AccessExecutes, Width: 4},
+			"14 cells, 4 per row: executes from 1 (dark) to 3 (light) on a log scale"},
+		{Heatmap{Access: AccessReads},
+			"14 cells, 32 per row: reads from 1 (dark) to 6 (light) on a log scale"},
+		{Heatmap{Combined: true, Width: 7},
+			"14 cells, 7 per row: red writes (max 3), green executes (max 3), blue reads (max 6)"},
+	}
+	for _, tt := range tests {
+		tt.heatmap.Profile = profiled(t, loop)
+		if got := tt.heatmap.Legend(); got != tt.want {
+			t.Errorf("legend %q, want %q", got, tt.want)
+		}
+	}
+}
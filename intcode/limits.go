@@ -0,0 +1,169 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// contextCheckInterval is how many instructions run between two checks of the context
+const contextCheckInterval = 1024
+
+var (
+	// ErrStepLimit is matched by errors.Is when a computer executed its maximum number of instructions
+	ErrStepLimit = errors.New("instruction limit exceeded")
+	// ErrAddressLimit is matched by errors.Is when a computer accessed memory above its highest address
+	ErrAddressLimit = errors.New("address limit exceeded")
+	// ErrOutputLimit is matched by errors.Is when a computer wanted to output more values than allowed
+	ErrOutputLimit = errors.New("output limit exceeded")
+)
+
+// Limits sandboxes a computer. A zero field means no limit.
+type Limits struct {
+	// MaxSteps is the number of instructions the computer may execute
+	MaxSteps int
+	// MaxAddress is the highest memory address the computer may access. Memory grows on demand up to it.
+	MaxAddress int
+	// MaxOutputs is the number of values the computer may output
+	MaxOutputs int
+	// Context stops the computer once it is done, including while it blocks on input or output
+	Context context.Context
+}
+
+// WithLimits sandboxes the computer, see Limits
+func WithLimits(limits Limits) Option {
+	return func(o *options) {
+		o.limits = limits
+	}
+}
+
+// WithContext stops the computer once the context is done
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.limits.Context = ctx
+	}
+}
+
+// Limit names the limit that terminated a computer
+type Limit int
+
+const (
+	// LimitSteps is the instruction count, see Limits.MaxSteps
+	LimitSteps Limit = iota
+	// LimitAddress is the highest memory address, see Limits.MaxAddress
+	LimitAddress
+	// LimitOutputs is the output count, see Limits.MaxOutputs
+	LimitOutputs
+	// LimitContext is the cancellation or deadline of Limits.Context
+	LimitContext
+)
+
+func (l Limit) String() string {
+	switch l {
+	case LimitSteps:
+		return "steps"
+	case LimitAddress:
+		return "address"
+	case LimitOutputs:
+		return "outputs"
+	case LimitContext:
+		return "context"
+	default:
+		return fmt.Sprintf("Limit(%d)", int(l))
+	}
+}
+
+// LimitError is the termination reason of a computer that exceeded a limit. The instruction pointer is left on the
+// instruction that was not executed.
+type LimitError struct {
+	Limit Limit
+	IP    int
+	Steps int
+	// Address is the memory address that was out of bounds for LimitAddress
+	Address int
+	// Cause is the context error for LimitContext
+	Cause error
+}
+
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case LimitAddress:
+		return fmt.Sprintf("%v: address %d at instruction %d after %d steps", ErrAddressLimit, e.Address, e.IP, e.Steps)
+	case LimitContext:
+		return fmt.Sprintf("%v at instruction %d after %d steps", e.Cause, e.IP, e.Steps)
+	default:
+		return fmt.Sprintf("%v at instruction %d after %d steps", e.Unwrap(), e.IP, e.Steps)
+	}
+}
+
+// Unwrap returns ErrStepLimit, ErrAddressLimit, ErrOutputLimit or the context error
+func (e *LimitError) Unwrap() error {
+	switch e.Limit {
+	case LimitSteps:
+		return ErrStepLimit
+	case LimitAddress:
+		return ErrAddressLimit
+	case LimitOutputs:
+		return ErrOutputLimit
+	default:
+		return e.Cause
+	}
+}
+
+// limitExceeded unwinds an instruction that hit a limit or broke the spec half way, E recovers it
+type limitExceeded struct {
+	err error
+}
+
+// exceed aborts the current instruction with the limit
+func (c *Machine[W, A]) exceed(limit Limit, address int, cause error) {
+	panic(limitExceeded{&LimitError{Limit: limit, IP: c.ip, Steps: c.steps, Address: address, Cause: cause}})
+}
+
+// cell returns the memory cell at the address, growing memory when the address is within the limits
+func (c *Machine[W, A]) cell(address int) *W {
+	if c.limits.MaxAddress > 0 && address > c.limits.MaxAddress {
+		c.exceed(LimitAddress, address, nil)
+	}
+	if c.spec != SpecDay09 && address >= c.programLen {
+		c.violate("address %d beyond the program", address)
+	}
+	if address > c.maxAddress {
+		c.maxAddress = address
+	}
+	if address >= len(c.memory) {
+		size := 2 * len(c.memory)
+		if size <= address {
+			size = address + 1
+		}
+		if c.limits.MaxAddress > 0 && size > c.limits.MaxAddress+1 {
+			size = c.limits.MaxAddress + 1
+		}
+		mem := make([]W, size)
+		copy(mem, c.memory)
+		c.memory = mem
+	}
+	return &c.memory[address]
+}
+
+// checkLimits runs before every instruction
+func (c *Machine[W, A]) checkLimits() {
+	if c.limits.MaxSteps > 0 && c.steps >= c.limits.MaxSteps {
+		c.exceed(LimitSteps, 0, nil)
+	}
+	if c.limits.Context != nil && (c.steps%contextCheckInterval == 0 || c.ctl.interrupted) {
+		if err := c.limits.Context.Err(); err != nil {
+			c.exceed(LimitContext, 0, err)
+		}
+	}
+}
+
+// Err returns the LimitError or SpecError that terminated the computer, or nil
+func (c *Machine[W, A]) Err() error {
+	return c.err
+}
+
+// Steps returns the number of instructions the computer executed
+func (c *Machine[W, A]) Steps() int {
+	return c.steps
+}
@@ -0,0 +1,78 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		limits  Limits
+		want    error
+		limit   Limit
+		ip      int
+		address int
+	}{
+		{"steps", []int{1105, 1, 0}, Limits{MaxSteps: 10}, ErrStepLimit, LimitSteps, 0, 0},
+		{"address", []int{1101, 1, 1, 5000, 99}, Limits{MaxAddress: 4096}, ErrAddressLimit, LimitAddress, 0, 5000},
+		{"relative address", []int{109, 4000, 21101, 1, 1, 100, 99}, Limits{MaxAddress: 4096}, ErrAddressLimit,
+			LimitAddress, 2, 4100},
+		{"outputs", []int{104, 1, 1105, 1, 0}, Limits{MaxOutputs: 3}, ErrOutputLimit, LimitOutputs, 0, 0},
+		{"within the limits", []int{104, 1, 99}, Limits{MaxSteps: 2, MaxAddress: 2, MaxOutputs: 1}, nil, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComputer(tt.program, WithLimits(tt.limits))
+			result, err := c.Run()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error %v, want %v", err, tt.want)
+			}
+			if tt.want == nil {
+				if result.Reason != HaltOpcode {
+					t.Errorf("stopped with %v, want halt", result.Reason)
+				}
+				return
+			}
+			var limit *LimitError
+			if !errors.As(err, &limit) || result.Reason != HaltLimit {
+				t.Fatalf("error %v with reason %v, want a LimitError", err, result.Reason)
+			}
+			if limit.Limit != tt.limit || limit.IP != tt.ip || limit.Address != tt.address {
+				t.Errorf("limit %v at %d address %d, want %v at %d address %d", limit.Limit, limit.IP, limit.Address,
+					tt.limit, tt.ip, tt.address)
+			}
+			// a terminated computer does not execute anymore
+			if !c.E() || c.Err() != err {
+				t.Errorf("terminated computer executed again")
+			}
+		})
+	}
+}
+
+func TestContextLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+	}{
+		{"running", []int{1105, 1, 0}},
+		{"blocked on input", []int{3, 0, 99}},
+		{"blocked on output", []int{104, 1, 104, 2, 99}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			// nobody writes the input or reads the output
+			c := MakeComputer(tt.program, make(chan int), make(chan int), WithContext(ctx))
+			_, err := c.Run()
+			var limit *LimitError
+			if !errors.As(err, &limit) || limit.Limit != LimitContext || !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("error %v, want a context LimitError", err)
+			}
+		})
+	}
+}
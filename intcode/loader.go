@@ -0,0 +1,191 @@
+package intcode
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError reports an invalid token of a program
+type SyntaxError struct {
+	// Line and Column of the token, starting at 1
+	Line, Column int
+	// Index is the position the value would have had in the program
+	Index int
+	Token string
+	Msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d column %d (value %d): %s %q", e.Line, e.Column, e.Index, e.Msg, e.Token)
+}
+
+// LoadProgram reads the program file at path, or stdin when the path is "-". Gzip compressed and binary files are
+// detected by their magic number. Failing to close the file is an error too.
+func LoadProgram(path string) (program []int, err error) {
+	if path == "-" {
+		return ReadProgram(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			program, err = nil, closeErr
+		}
+	}()
+
+	program, err = ReadProgram(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return program, nil
+}
+
+// ReadProgram parses a program of integers separated by commas, whitespace or both, so a program may span several
+// lines. Comments start with '#' or "//" and run to the end of the line. A single trailing comma is allowed.
+// Gzip compressed input is decompressed first, and programs in the binary format are decoded.
+func ReadProgram(r io.Reader) ([]int, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	}
+	if magic, err := br.Peek(len(binaryMagic)); err == nil && IsBinary(magic) {
+		return DecodeProgram(br)
+	}
+
+	p := programParser{r: br, line: 1}
+	return p.parse()
+}
+
+// ParseProgram parses a program held in a string, see ReadProgram
+func ParseProgram(str string) ([]int, error) {
+	return ReadProgram(strings.NewReader(str))
+}
+
+type programParser struct {
+	r *bufio.Reader
+	// line and column of the next byte
+	line, column int
+
+	program []int
+	// pendingComma is set after a comma until the next value
+	pendingComma bool
+}
+
+func (p *programParser) next() (byte, error) {
+	b, err := p.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b == '\n' {
+		p.line++
+		p.column = 0
+	} else {
+		p.column++
+	}
+	return b, nil
+}
+
+func (p *programParser) errorf(line, column int, token, msg string) error {
+	return &SyntaxError{Line: line, Column: column, Index: len(p.program), Token: token, Msg: msg}
+}
+
+func (p *programParser) parse() ([]int, error) {
+	for {
+		b, err := p.next()
+		if err == io.EOF {
+			return p.program, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case b == ' ' || b == '\t' || b == '\r' || b == '\n':
+		case b == '#':
+			if err := p.skipLine(); err != nil {
+				return nil, err
+			}
+		case b == '/':
+			line, column := p.line, p.column
+			if next, err := p.r.Peek(1); err != nil || next[0] != '/' {
+				return nil, p.errorf(line, column, "/", "unexpected character")
+			}
+			if err := p.skipLine(); err != nil {
+				return nil, err
+			}
+		case b == ',':
+			if p.pendingComma || len(p.program) == 0 {
+				return nil, p.errorf(p.line, p.column, ",", "missing value before")
+			}
+			p.pendingComma = true
+		default:
+			if err := p.value(b); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (p *programParser) skipLine() error {
+	for {
+		b, err := p.next()
+		if err == io.EOF || b == '\n' {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// value reads the token starting with b up to the next separator
+func (p *programParser) value(b byte) error {
+	line, column := p.line, p.column
+	token := []byte{b}
+	for {
+		next, err := p.r.Peek(1)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		c := next[0]
+		if c == ',' || c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '#' {
+			break
+		}
+		// a "//" comment may follow the value directly, a single slash is part of the invalid token
+		if pair, _ := p.r.Peek(2); c == '/' && len(pair) == 2 && pair[1] == '/' {
+			break
+		}
+		if _, err := p.next(); err != nil {
+			return err
+		}
+		token = append(token, c)
+	}
+
+	val, err := strconv.Atoi(string(token))
+	if err != nil {
+		msg := "invalid value"
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			msg = "value out of range"
+		}
+		return p.errorf(line, column, string(token), msg)
+	}
+	p.program = append(p.program, val)
+	p.pendingComma = false
+	return nil
+}
@@ -0,0 +1,109 @@
+package intcode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseProgram(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []int
+	}{
+		{"commas", "1,2,3", []int{1, 2, 3}},
+		{"newline at the end", "1,0,0,0,99\n", []int{1, 0, 0, 0, 99}},
+		{"whitespace", "1 2\t3\r\n4", []int{1, 2, 3, 4}},
+		{"commas and lines", "1, 2,\n3,\n4", []int{1, 2, 3, 4}},
+		{"trailing comma", "1,2,", []int{1, 2}},
+		{"negative", "-1,+2", []int{-1, 2}},
+		{"hash comment line", "# header\n1,2\n", []int{1, 2}},
+		{"hash comment after a space", "1,2 # note\n3", []int{1, 2, 3}},
+		{"hash comment after a value", "1,2# note\n3", []int{1, 2, 3}},
+		{"slash comment after a space", "1,2 // note\n3", []int{1, 2, 3}},
+		{"slash comment after a value", "1,2//note\n3", []int{1, 2, 3}},
+		{"slash comment after a comma", "1,2,//note\n3", []int{1, 2, 3}},
+		{"slash comment at the end", "1,2//", []int{1, 2}},
+		{"empty", "", nil},
+		{"only a comment", "// nothing\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := ParseProgram(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(program, tt.want) {
+				t.Errorf("program %v, want %v", program, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProgramErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want SyntaxError
+	}{
+		{"double comma", "1,,2", SyntaxError{Line: 1, Column: 3, Index: 1, Token: ",", Msg: "missing value before"}},
+		{"leading comma", ",1", SyntaxError{Line: 1, Column: 1, Index: 0, Token: ",", Msg: "missing value before"}},
+		{"letter", "1,\n2,x", SyntaxError{Line: 2, Column: 3, Index: 2, Token: "x", Msg: "invalid value"}},
+		{"single slash", "1 / 2", SyntaxError{Line: 1, Column: 3, Index: 1, Token: "/", Msg: "unexpected character"}},
+		{"slash in a value", "1,2/3", SyntaxError{Line: 1, Column: 3, Index: 1, Token: "2/3", Msg: "invalid value"}},
+		{"slash at the end", "1,2/", SyntaxError{Line: 1, Column: 3, Index: 1, Token: "2/", Msg: "invalid value"}},
+		{"out of range", "1,99999999999999999999", SyntaxError{Line: 1, Column: 3, Index: 1,
+			Token: "99999999999999999999", Msg: "value out of range"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseProgram(tt.text)
+			var syntax *SyntaxError
+			if !errors.As(err, &syntax) {
+				t.Fatalf("error %v, want a SyntaxError", err)
+			}
+			if *syntax != tt.want {
+				t.Errorf("error %+v, want %+v", *syntax, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProgram(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write([]byte("1,0,0,0,\n99\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"plain.txt": []byte("1,0,0,0,99\n"),
+		"input.gz":  compressed.Bytes(),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		program, err := LoadProgram(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(program, []int{1, 0, 0, 0, 99}) {
+			t.Errorf("%s: program %v", name, program)
+		}
+	}
+
+	if _, err := LoadProgram(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Errorf("loaded a missing file")
+	}
+}
@@ -0,0 +1,167 @@
+package intcode
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+)
+
+// Subsystem names a part of a machine that logs, each one can have its own level, see LogConfig
+type Subsystem string
+
+const (
+	// SubsystemExec logs every executed instruction at debug level
+	SubsystemExec Subsystem = "exec"
+	// SubsystemIO logs input and output values, suspending on input and closed input at debug level
+	SubsystemIO Subsystem = "io"
+	// SubsystemLimits logs computers terminated by a limit or the spec at debug level
+	SubsystemLimits Subsystem = "limits"
+	// SubsystemNative logs when RunNative falls back to the interpreter at debug level
+	SubsystemNative Subsystem = "native"
+)
+
+// subsystems are all subsystems, in the order of machineLogs
+var subsystems = []Subsystem{SubsystemExec, SubsystemIO, SubsystemLimits, SubsystemNative}
+
+// LogConfig sets the log level of each subsystem. Subsystems without a level of their own use Level.
+type LogConfig struct {
+	Level      zapcore.Level               `json:"level" yaml:"level"`
+	Subsystems map[Subsystem]zapcore.Level `json:"subsystems,omitempty" yaml:"subsystems,omitempty"`
+}
+
+// ParseLogConfig reads a comma separated list of levels like "info,exec=debug,io=warn". A level without a subsystem
+// is the default level, which is info when missing.
+func ParseLogConfig(s string) (LogConfig, error) {
+	config := LogConfig{Level: zapcore.InfoLevel}
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		name, levelName, ok := strings.Cut(field, "=")
+		if !ok {
+			name, levelName = "", field
+		}
+		var level zapcore.Level
+		if err := level.UnmarshalText([]byte(levelName)); err != nil {
+			return LogConfig{}, err
+		}
+		if name == "" {
+			config.Level = level
+			continue
+		}
+		if !knownSubsystem(Subsystem(name)) {
+			return LogConfig{}, fmt.Errorf("unknown log subsystem %q, want one of %v", name, subsystems)
+		}
+		if config.Subsystems == nil {
+			config.Subsystems = make(map[Subsystem]zapcore.Level)
+		}
+		config.Subsystems[Subsystem(name)] = level
+	}
+	return config, nil
+}
+
+func knownSubsystem(s Subsystem) bool {
+	for _, known := range subsystems {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// level returns the level of the subsystem
+func (lc LogConfig) level(s Subsystem) zapcore.Level {
+	if level, ok := lc.Subsystems[s]; ok {
+		return level
+	}
+	return lc.Level
+}
+
+// WithLogger logs to the logger, each subsystem as a named child logger. Without the option a machine does not log
+// at all and pays nothing for it.
+func WithLogger(logger *zap.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// loggerOf returns the logger of WithLogger among the options, a no-op logger without one
+func loggerOf(opts []Option) *zap.Logger {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return orNop(o.logger)
+}
+
+// orNop returns the logger, or a no-op logger when it is nil
+func orNop(logger *zap.Logger) *zap.Logger {
+	if logger == nil {
+		return zap.NewNop()
+	}
+	return logger
+}
+
+// WithLogConfig filters the log entries of each subsystem by its level. Entries the logger itself does not enable
+// are still dropped.
+func WithLogConfig(config LogConfig) Option {
+	return func(o *options) {
+		o.logConfig = &config
+	}
+}
+
+// machineLogs are the loggers of the subsystems, no-op loggers when the machine has no logger
+type machineLogs struct {
+	exec, io, limits, native *zap.Logger
+	// execEnabled saves the per instruction level check when exec logging is off
+	execEnabled bool
+}
+
+var nopLogs = machineLogs{exec: zap.NewNop(), io: zap.NewNop(), limits: zap.NewNop(), native: zap.NewNop()}
+
+// newMachineLogs derives the subsystem loggers from the logger and the config
+func newMachineLogs(logger *zap.Logger, config *LogConfig) machineLogs {
+	if logger == nil {
+		return nopLogs
+	}
+	named := func(s Subsystem) *zap.Logger {
+		l := logger.Named(string(s))
+		if config != nil {
+			level := config.level(s)
+			l = l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+				return levelCore{core, level}
+			}))
+		}
+		return l
+	}
+	logs := machineLogs{
+		exec:   named(SubsystemExec),
+		io:     named(SubsystemIO),
+		limits: named(SubsystemLimits),
+		native: named(SubsystemNative),
+	}
+	logs.execEnabled = logs.exec.Core().Enabled(zapcore.DebugLevel)
+	return logs
+}
+
+// levelCore drops the entries below a level, the zap version in use has no zap.IncreaseLevel
+type levelCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+func (c levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) && c.Core.Enabled(level)
+}
+
+func (c levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelCore{c.Core.With(fields), c.level}
+}
+
+func (c levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
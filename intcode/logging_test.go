@@ -0,0 +1,121 @@
+package intcode
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"reflect"
+	"testing"
+)
+
+func TestParseLogConfig(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    LogConfig
+		wantErr bool
+	}{
+		{"", LogConfig{Level: zapcore.InfoLevel}, false},
+		{"warn", LogConfig{Level: zapcore.WarnLevel}, false},
+		{"info,exec=debug, io=warn", LogConfig{Level: zapcore.InfoLevel,
+			Subsystems: map[Subsystem]zapcore.Level{SubsystemExec: zapcore.DebugLevel, SubsystemIO: zapcore.WarnLevel}}, false},
+		{"native=error,debug", LogConfig{Level: zapcore.DebugLevel,
+			Subsystems: map[Subsystem]zapcore.Level{SubsystemNative: zapcore.ErrorLevel}}, false},
+		{"loud", LogConfig{}, true},
+		{"disk=debug", LogConfig{}, true},
+		{"=debug", LogConfig{Level: zapcore.DebugLevel}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseLogConfig(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: parsed %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMachineLogs(t *testing.T) {
+	tests := []struct {
+		config string
+		// counts are the number of entries of each subsystem
+		counts map[string]int
+	}{
+		{"debug", map[string]int{"exec": 3, "io": 2}},
+		{"warn,io=debug", map[string]int{"io": 2}},
+		{"info", map[string]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			config, err := ParseLogConfig(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			core, logs := observer.New(zapcore.DebugLevel)
+			c := NewComputer([]int{3, 5, 4, 5, 99, 0}, WithLogger(zap.New(core)), WithLogConfig(config))
+			c.Send(7)
+			if _, err := c.Run(); err != nil {
+				t.Fatal(err)
+			}
+			counts := make(map[string]int)
+			for _, entry := range logs.All() {
+				counts[entry.LoggerName]++
+			}
+			if !reflect.DeepEqual(counts, tt.counts) {
+				t.Errorf("entries %v, want %v", counts, tt.counts)
+			}
+		})
+	}
+}
+
+func TestComponentLogs(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(logger *zap.Logger)
+		message string
+		level   zapcore.Level
+	}{
+		{"scheduler", func(logger *zap.Logger) {
+			s := &Scheduler{Logger: logger}
+			s.Spawn([]int{42}, func(int) {})
+			s.Run()
+		}, "process killed", zapcore.WarnLevel},
+		{"network", func(logger *zap.Logger) {
+			n := NewNetwork()
+			n.SetLogger(logger)
+			n.AddNode("a", []int{104, 1, 99})
+			n.Run()
+		}, "node stopped", zapcore.DebugLevel},
+		{"router", func(logger *zap.Logger) {
+			NewRouter([]int{99}, 1, WithLogger(logger)).Send(5, 1, 2)
+		}, "dropping packet for unknown address", zapcore.WarnLevel},
+		{"sweep", func(logger *zap.Logger) {
+			s := Sweep{Program: []int{104, 0, 99}, Patches: []Patch{{Address: 0, Min: 98, Max: 98}},
+				Target: LastOutputEquals(0), Logger: logger}
+			s.Run(context.Background())
+		}, "patched program stopped", zapcore.DebugLevel},
+		{"symbolic", func(logger *zap.Logger) {
+			s := NewSymbolic([]int{3, 9, 1008, 9, 5, 10, 4, 10, 99, 0, 0})
+			s.SetLogger(logger)
+			s.InputVar("a", 0, 10)
+			s.SolveOutput(0, 1)
+		}, "falling back to enumeration", zapcore.DebugLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			tt.run(zap.New(core))
+			entries := logs.FilterMessage(tt.message).All()
+			if len(entries) == 0 {
+				t.Fatalf("no %q entry in %v", tt.message, logs.All())
+			}
+			if entries[0].Level != tt.level {
+				t.Errorf("logged at %v, want %v", entries[0].Level, tt.level)
+			}
+			// without a logger nothing is logged, which must not fail either
+			tt.run(nil)
+		})
+	}
+}
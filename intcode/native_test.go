@@ -0,0 +1,226 @@
+package intcode_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// nativeTests are the day 5 and day 9 examples and the day 5 diagnostic program
+var nativeTests = []struct {
+	name string
+	file string
+	opts intcode.TranspileOptions
+	// inputs are the runs to compare with the interpreter
+	inputs [][]int
+	// outputs are the known outputs for the inputs, nil to only compare with the interpreter
+	outputs [][]int
+}{
+	{"compare8", "compare8.txt", intcode.TranspileOptions{},
+		[][]int{{7}, {8}, {9}}, [][]int{{999}, {1000}, {1001}}},
+	{"compare8 optimized", "compare8.txt", intcode.TranspileOptions{Optimize: true, Inputs: [][]int{{7}, {8}, {9}}},
+		[][]int{{7}, {8}, {9}}, [][]int{{999}, {1000}, {1001}}},
+	{"jumps", "jumps.txt", intcode.TranspileOptions{},
+		[][]int{{0}, {5}}, [][]int{{0}, {1}}},
+	{"quine", "quine.txt", intcode.TranspileOptions{},
+		[][]int{nil}, [][]int{{109, 1, 204, -1, 1001, 100, 1, 100, 1008, 100, 16, 101, 1006, 101, 0, 99}}},
+	{"large", "large.txt", intcode.TranspileOptions{},
+		[][]int{nil}, [][]int{{1219070632396864}}},
+	{"diagnostic", "diagnostic.txt", intcode.TranspileOptions{},
+		[][]int{{1}, {5}}, nil},
+	{"diagnostic optimized", "diagnostic.txt", intcode.TranspileOptions{Optimize: true, Inputs: [][]int{{1}, {5}}},
+		[][]int{{1}, {5}}, nil},
+}
+
+// nativeMain runs every program with the interpreter and as translated code and prints a nativeRun for each input
+const nativeMain = `package main
+
+import (
+	"encoding/json"
+	"github.com/ljdelight/adventOfCode-2019/intcode"
+	"os"
+	"reflect"
+)
+
+type run struct {
+	Program      int
+	Input        []int
+	Reason       string
+	NativeReason string
+	Output       []int
+	NativeOutput []int
+	SameMemory   bool
+	Calls        int
+	Err          string
+}
+
+func compare(program int, compiled *intcode.Compiled, input []int) run {
+	r := run{Program: program, Input: input}
+	c := intcode.NewComputer(compiled.Program)
+	c.Send(input...)
+	c.CloseInput()
+	result, err := c.Run()
+	if err != nil {
+		r.Err = err.Error()
+		return r
+	}
+	r.Reason, r.Output = result.Reason.String(), c.TakeOutput()
+	memory := c.Snapshot().Memory
+
+	// count the calls to be sure the translated code runs
+	counted := *compiled
+	counted.Run = func(n *intcode.Native) {
+		r.Calls++
+		compiled.Run(n)
+	}
+	c = intcode.NewComputer(compiled.Program)
+	c.Send(input...)
+	c.CloseInput()
+	if result, err = c.RunNative(&counted); err != nil {
+		r.Err = err.Error()
+		return r
+	}
+	r.NativeReason, r.NativeOutput = result.Reason.String(), c.TakeOutput()
+	// translated code grows memory up front
+	native := c.Snapshot().Memory
+	r.SameMemory = len(native) >= len(memory) && reflect.DeepEqual(native[:len(memory)], memory)
+	return r
+}
+
+func main() {
+	var runs []run
+%s	json.NewEncoder(os.Stdout).Encode(runs)
+}
+`
+
+// nativeRun is the comparison of one input printed by nativeMain
+type nativeRun struct {
+	Program      int
+	Input        []int
+	Reason       string
+	NativeReason string
+	Output       []int
+	NativeOutput []int
+	SameMemory   bool
+	Calls        int
+	Err          string
+}
+
+// TestRunNative transpiles the programs into a module next to this one and runs them there, so the generated code
+// is compiled and run without checking it in
+func TestRunNative(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the transpiled programs")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go tool")
+	}
+	module, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := ioutil.ReadFile("go.sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module native\n\ngo 1.18\n\nrequire github.com/ljdelight/adventOfCode-2019/intcode v0.0.0\n\n" +
+			"replace github.com/ljdelight/adventOfCode-2019/intcode => " + module + "\n",
+		"go.sum": string(sum),
+	}
+	var calls strings.Builder
+	for i, tt := range nativeTests {
+		program, err := intcode.LoadProgram(filepath.Join("testdata", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := tt.opts
+		opts.Var = fmt.Sprintf("program%d", i)
+		var src bytes.Buffer
+		if err := intcode.Transpile(&src, program, opts); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		files[opts.Var+".go"] = src.String()
+		for _, input := range tt.inputs {
+			fmt.Fprintf(&calls, "\truns = append(runs, compare(%d, %s, %#v))\n", i, opts.Var, input)
+		}
+	}
+	files["main.go"] = fmt.Sprintf(nativeMain, calls.String())
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goTool, "run", "-mod=mod", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("running the transpiled programs: %v\n%s", err, stderr.String())
+	}
+	var runs []nativeRun
+	if err := json.Unmarshal(out, &runs); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range runs {
+		tt := nativeTests[r.Program]
+		t.Run(fmt.Sprintf("%s %v", tt.name, r.Input), func(t *testing.T) {
+			if r.Err != "" {
+				t.Fatal(r.Err)
+			}
+			if tt.outputs != nil {
+				for i, input := range tt.inputs {
+					if reflect.DeepEqual(input, r.Input) && !reflect.DeepEqual(r.Output, tt.outputs[i]) {
+						t.Errorf("interpreter output %v, want %v", r.Output, tt.outputs[i])
+					}
+				}
+			}
+			if r.Calls == 0 {
+				t.Errorf("the translated code never ran")
+			}
+			if r.NativeReason != r.Reason {
+				t.Errorf("stopped with %v, want %v", r.NativeReason, r.Reason)
+			}
+			if !reflect.DeepEqual(r.NativeOutput, r.Output) {
+				t.Errorf("output %v, want %v", r.NativeOutput, r.Output)
+			}
+			if !r.SameMemory {
+				t.Errorf("memory differs from the interpreter")
+			}
+		})
+	}
+	want := 0
+	for _, tt := range nativeTests {
+		want += len(tt.inputs)
+	}
+	if len(runs) != want {
+		t.Errorf("%d runs, want %d", len(runs), want)
+	}
+}
+
+func TestTranspileVerifies(t *testing.T) {
+	// the jump goes to the address 15 it computes, where the optimizer propagates the 7 written on the way there
+	program := []int{1101, 5, 0, 30, 1102, 3, 5, 31, 5, 30, 31, 1101, 7, 0, 30, 4, 30, 99, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0}
+	err := intcode.Transpile(ioutil.Discard, program, intcode.TranspileOptions{Optimize: true})
+	var mismatch *intcode.MismatchError
+	if !errors.As(err, &mismatch) {
+		t.Errorf("error %v, want a MismatchError", err)
+	}
+}
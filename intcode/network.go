@@ -0,0 +1,340 @@
+package intcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// NodeState describes how a node in a network stopped running
+type NodeState int
+
+const (
+	// NodeHalted means the program executed the HALT instruction
+	NodeHalted NodeState = iota
+	// NodeInputClosed means the program wanted input after every upstream node finished
+	NodeInputClosed
+	// NodeStopped means the program was waiting for input when the network stopped
+	NodeStopped
+	// NodeKilled means the program exceeded one of the network limits or faulted, see NodeResult.Err
+	NodeKilled
+)
+
+func (s NodeState) String() string {
+	switch s {
+	case NodeHalted:
+		return "halted"
+	case NodeInputClosed:
+		return "input closed"
+	case NodeStopped:
+		return "stopped"
+	case NodeKilled:
+		return "killed"
+	default:
+		return fmt.Sprintf("NodeState(%d)", int(s))
+	}
+}
+
+// NodeSpec describes a single computer of a topology file. The program is either given inline as Code
+// or loaded from the Program path, which is relative to the topology file.
+type NodeSpec struct {
+	Name    string `json:"name" yaml:"name"`
+	Program string `json:"program,omitempty" yaml:"program,omitempty"`
+	Code    []int  `json:"code,omitempty" yaml:"code,omitempty"`
+	Inputs  []int  `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+}
+
+// EdgeSpec connects the output of the From node to the input of the To node
+type EdgeSpec struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// Topology is the declarative form of a Network
+type Topology struct {
+	Nodes []NodeSpec `json:"nodes" yaml:"nodes"`
+	Edges []EdgeSpec `json:"edges" yaml:"edges"`
+}
+
+// LoadTopology reads a YAML (.yaml, .yml) or JSON topology file and resolves the node programs
+func LoadTopology(path string) (*Topology, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var t Topology
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &t)
+	default:
+		err = json.Unmarshal(data, &t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse topology %s: %v", path, err)
+	}
+
+	for i := range t.Nodes {
+		node := &t.Nodes[i]
+		if node.Code != nil || node.Program == "" {
+			continue
+		}
+		programPath := node.Program
+		if !filepath.IsAbs(programPath) {
+			programPath = filepath.Join(filepath.Dir(path), programPath)
+		}
+		node.Code, err = LoadProgram(programPath)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %v", node.Name, err)
+		}
+	}
+	return &t, nil
+}
+
+// Network builds a Network from the topology
+func (t *Topology) Network() (*Network, error) {
+	n := NewNetwork()
+	for _, node := range t.Nodes {
+		if node.Code == nil {
+			return nil, fmt.Errorf("node %s has no program", node.Name)
+		}
+		if err := n.AddNode(node.Name, node.Code, node.Inputs...); err != nil {
+			return nil, err
+		}
+	}
+	for _, edge := range t.Edges {
+		if err := n.Connect(edge.From, edge.To); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// Network is a set of computers whose outputs are wired to the inputs of other computers. An output may feed
+// several nodes (fan-out) and an input may be fed by several nodes (fan-in).
+type Network struct {
+	nodes  []*networkNode
+	byName map[string]*networkNode
+	idle   IdleHandler
+	limits Limits
+	logger *zap.Logger
+}
+
+type networkNode struct {
+	name    string
+	memory  []int
+	inputs  []int
+	targets []*networkNode
+	sources []*networkNode
+}
+
+// NodeResult is the outcome of a single node after the network ran
+type NodeResult struct {
+	Outputs []int
+	State   NodeState
+	// Err is the LimitError, SpecError or FaultError of a killed node
+	Err error
+}
+
+// NewNetwork creates an empty network
+func NewNetwork() *Network {
+	return &Network{byName: make(map[string]*networkNode)}
+}
+
+// AddNode adds a computer running the program. The initial inputs are queued before the network starts.
+func (n *Network) AddNode(name string, memory []int, inputs ...int) error {
+	if _, ok := n.byName[name]; ok {
+		return fmt.Errorf("duplicate node %s", name)
+	}
+	node := &networkNode{name: name, memory: memory, inputs: inputs}
+	n.nodes = append(n.nodes, node)
+	n.byName[name] = node
+	return nil
+}
+
+// Connect sends every output of the from node to the input of the to node
+func (n *Network) Connect(from, to string) error {
+	src, ok := n.byName[from]
+	if !ok {
+		return fmt.Errorf("unknown node %s", from)
+	}
+	dst, ok := n.byName[to]
+	if !ok {
+		return fmt.Errorf("unknown node %s", to)
+	}
+	src.targets = append(src.targets, dst)
+	dst.sources = append(dst.sources, src)
+	return nil
+}
+
+// OnIdle registers a handler that is called when every running node waits for input. Without a handler an idle
+// network is a deadlock and Run returns a *DeadlockError.
+func (n *Network) OnIdle(handler IdleHandler) {
+	n.idle = handler
+}
+
+// SetLimits sandboxes every node. A node that exceeds a limit is killed and counts as stopped for its targets.
+func (n *Network) SetLimits(limits Limits) {
+	n.limits = limits
+}
+
+// SetLogger logs the stopped nodes and idle network at debug level and gives every node the logger, see WithLogger
+func (n *Network) SetLogger(logger *zap.Logger) {
+	n.logger = logger
+}
+
+// WaitingNode is a node blocked on an INPUT instruction
+type WaitingNode struct {
+	Node string
+	// Address of the INPUT instruction
+	Address int
+	// Sources are the nodes whose outputs feed the input
+	Sources []string
+}
+
+func (w WaitingNode) String() string {
+	return fmt.Sprintf("%s waits at address %d on input from %v", w.Node, w.Address, w.Sources)
+}
+
+// DeadlockError is returned when every running node waits for input that can never arrive
+type DeadlockError struct {
+	Waiting []WaitingNode
+}
+
+func (e *DeadlockError) Error() string {
+	var b bytes.Buffer
+	b.WriteString("network deadlock: ")
+	for i, w := range e.Waiting {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(w.String())
+	}
+	return b.String()
+}
+
+// IdleHandler is called when the network is quiescent: every node that has not stopped waits for input and every
+// queue is empty. It may queue inputs with Idle.Send and returns false to stop the network.
+type IdleHandler func(idle *Idle) bool
+
+// Idle is the state handed to an IdleHandler. It is only valid for the duration of the call.
+type Idle struct {
+	Waiting []WaitingNode
+	run     *networkRun
+}
+
+// Send queues values on the input of the named node
+func (i *Idle) Send(name string, values ...int) error {
+	node, ok := i.run.byName[name]
+	if !ok {
+		return fmt.Errorf("unknown node %s", name)
+	}
+	node.proc.Send(values...)
+	return nil
+}
+
+// networkRun is the state of a single Run
+type networkRun struct {
+	nodes  []*runningNode
+	byName map[string]*runningNode
+}
+
+type runningNode struct {
+	*networkNode
+	proc    *Process
+	targets []*runningNode
+	// upstream is the number of feeding nodes that have not stopped yet
+	upstream int
+	result   *NodeResult
+}
+
+// Run executes every node on a single goroutine until all of them stop. A node's input is closed once every node
+// feeding it has stopped and its queue has drained; nodes without incoming edges only see their initial inputs.
+// When every node that is still running waits for input the idle handler decides how to continue.
+func (n *Network) Run() (map[string]*NodeResult, error) {
+	r := &networkRun{byName: make(map[string]*runningNode, len(n.nodes))}
+	s := &Scheduler{Quantum: 1000, Logger: n.logger}
+	s.OnStop = func(p *Process) {
+		rn := r.nodes[p.ID]
+		s.log().Debug("node stopped", zap.String("node", rn.name), zap.Stringer("state", p.State()))
+		for _, target := range rn.targets {
+			target.upstream--
+			if target.upstream == 0 {
+				target.proc.CloseInput()
+			}
+		}
+	}
+
+	for _, node := range n.nodes {
+		rn := &runningNode{networkNode: node, upstream: len(node.sources), result: &NodeResult{}}
+		rn.proc = s.Spawn(node.memory, func(val int) {
+			rn.result.Outputs = append(rn.result.Outputs, val)
+			for _, target := range rn.targets {
+				target.proc.Send(val)
+			}
+		}, WithLimits(n.limits), WithLogger(n.logger))
+		rn.proc.Send(node.inputs...)
+		if rn.upstream == 0 {
+			rn.proc.CloseInput()
+		}
+		r.nodes = append(r.nodes, rn)
+		r.byName[node.name] = rn
+	}
+	for _, rn := range r.nodes {
+		for _, target := range rn.networkNode.targets {
+			rn.targets = append(rn.targets, r.byName[target.name])
+		}
+	}
+
+	var err error
+	for {
+		procs := s.Run()
+		if len(procs) == 0 {
+			break
+		}
+
+		var waiting []WaitingNode
+		for _, p := range procs {
+			rn := r.nodes[p.ID]
+			w := WaitingNode{Node: rn.name, Address: p.IP()}
+			for _, source := range rn.sources {
+				w.Sources = append(w.Sources, source.name)
+			}
+			waiting = append(waiting, w)
+		}
+
+		if n.idle != nil {
+			s.log().Debug("network idle", zap.Int("waiting", len(waiting)))
+			if !n.idle(&Idle{Waiting: waiting, run: r}) {
+				break
+			}
+			if len(s.runQueue) > 0 {
+				continue
+			}
+		}
+		err = &DeadlockError{Waiting: waiting}
+		break
+	}
+
+	results := make(map[string]*NodeResult, len(r.nodes))
+	for _, rn := range r.nodes {
+		switch rn.proc.State() {
+		case ProcessHalted:
+			rn.result.State = NodeHalted
+		case ProcessInputClosed:
+			rn.result.State = NodeInputClosed
+		case ProcessKilled:
+			rn.result.State = NodeKilled
+			rn.result.Err = rn.proc.Err()
+		default:
+			rn.result.State = NodeStopped
+		}
+		results[rn.name] = rn.result
+	}
+	return results, err
+}
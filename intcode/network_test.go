@@ -0,0 +1,244 @@
+package intcode
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// the amplifier examples of day 7
+var (
+	amplifiers = []int{3, 15, 3, 16, 1002, 16, 10, 16, 1, 16, 15, 15, 4, 15, 99, 0, 0}
+	feedback   = []int{3, 26, 1001, 26, -4, 26, 3, 27, 1002, 27, 2, 27, 1, 27, 26, 27, 4, 27, 1001, 28, -1, 28, 1005, 28,
+		6, 99, 0, 0, 5}
+)
+
+func TestNetworkAmplifiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		phases  []int
+		loop    bool
+		want    int
+	}{
+		{"chain", amplifiers, []int{4, 3, 2, 1, 0}, false, 43210},
+		{"feedback loop", feedback, []int{9, 8, 7, 6, 5}, true, 139629729},
+	}
+	names := []string{"A", "B", "C", "D", "E"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewNetwork()
+			for i, phase := range tt.phases {
+				inputs := []int{phase}
+				if i == 0 {
+					inputs = append(inputs, 0)
+				}
+				if err := n.AddNode(names[i], tt.program, inputs...); err != nil {
+					t.Fatal(err)
+				}
+				if i > 0 {
+					if err := n.Connect(names[i-1], names[i]); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if tt.loop {
+				if err := n.Connect("E", "A"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			results, err := n.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			outputs := results["E"].Outputs
+			if len(outputs) == 0 || outputs[len(outputs)-1] != tt.want {
+				t.Errorf("outputs of E %v, want the last to be %d", outputs, tt.want)
+			}
+			for name, result := range results {
+				if result.State != NodeHalted {
+					t.Errorf("node %s %v, want halted", name, result.State)
+				}
+			}
+		})
+	}
+}
+
+func TestNetworkFanOutAndIn(t *testing.T) {
+	// the source outputs 1 and 2, both doublers double them and the sink adds up what it reads until its input closes
+	n := NewNetwork()
+	nodes := []struct {
+		name    string
+		program []int
+	}{
+		{"source", []int{104, 1, 104, 2, 99}},
+		{"left", []int{3, 9, 102, 2, 9, 9, 4, 9, 99, 0}},
+		{"right", []int{3, 9, 102, 2, 9, 9, 4, 9, 99, 0}},
+		{"sink", []int{3, 12, 1, 12, 13, 13, 1105, 1, 0, 99, 0, 0, 0, 0}},
+	}
+	for _, node := range nodes {
+		if err := n.AddNode(node.name, node.program); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, edge := range [][2]string{{"source", "left"}, {"source", "right"}, {"left", "sink"}, {"right", "sink"}} {
+		if err := n.Connect(edge[0], edge[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err := n.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(results["left"].Outputs, []int{2}) || !reflect.DeepEqual(results["right"].Outputs, []int{2}) {
+		t.Errorf("doublers output %v and %v, want [2] each", results["left"].Outputs, results["right"].Outputs)
+	}
+	if results["sink"].State != NodeInputClosed {
+		t.Errorf("sink %v, want input closed", results["sink"].State)
+	}
+}
+
+func TestNetworkErrors(t *testing.T) {
+	n := NewNetwork()
+	if err := n.AddNode("a", []int{99}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.AddNode("a", []int{99}); err == nil {
+		t.Errorf("duplicate node added")
+	}
+	if err := n.Connect("a", "b"); err == nil {
+		t.Errorf("connected an unknown node")
+	}
+}
+
+func TestNetworkDeadlock(t *testing.T) {
+	n := NewNetwork()
+	for _, name := range []string{"a", "b"} {
+		if err := n.AddNode(name, []int{3, 0, 99}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := n.Connect("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Connect("b", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := n.Run()
+	var deadlock *DeadlockError
+	if !errors.As(err, &deadlock) {
+		t.Fatalf("error %v, want a DeadlockError", err)
+	}
+	want := []WaitingNode{{Node: "a", Address: 0, Sources: []string{"b"}}, {Node: "b", Address: 0, Sources: []string{"a"}}}
+	if !reflect.DeepEqual(deadlock.Waiting, want) {
+		t.Errorf("waiting %v, want %v", deadlock.Waiting, want)
+	}
+	if results["a"].State != NodeStopped {
+		t.Errorf("node a %v, want stopped", results["a"].State)
+	}
+}
+
+func TestNetworkIdle(t *testing.T) {
+	// both nodes read a value and output it plus one, the idle handler starts the exchange
+	increment := []int{3, 9, 101, 1, 9, 9, 4, 9, 99, 0}
+	tests := []struct {
+		name  string
+		start bool
+		want  map[string][]int
+		state NodeState
+	}{
+		{"kick off", true, map[string][]int{"a": {6}, "b": {7}}, NodeHalted},
+		{"stop", false, map[string][]int{"a": nil, "b": nil}, NodeStopped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := NewNetwork()
+			for _, name := range []string{"a", "b"} {
+				if err := n.AddNode(name, increment); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := n.Connect("a", "b"); err != nil {
+				t.Fatal(err)
+			}
+			if err := n.Connect("b", "a"); err != nil {
+				t.Fatal(err)
+			}
+			calls := 0
+			n.OnIdle(func(idle *Idle) bool {
+				calls++
+				if len(idle.Waiting) != 2 {
+					t.Errorf("idle with %v waiting, want both nodes", idle.Waiting)
+				}
+				if !tt.start {
+					return false
+				}
+				if err := idle.Send("x", 1); err == nil {
+					t.Errorf("sent to an unknown node")
+				}
+				return idle.Send("a", 5) == nil
+			})
+
+			results, err := n.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if calls != 1 {
+				t.Errorf("idle handler called %d times, want once", calls)
+			}
+			for name, want := range tt.want {
+				if !reflect.DeepEqual(results[name].Outputs, want) || results[name].State != tt.state {
+					t.Errorf("node %s %v with outputs %v, want %v with %v", name, results[name].State,
+						results[name].Outputs, tt.state, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadTopology(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "double.txt"), []byte("3,9,102,2,9,9,4,9,99,0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"net.yaml": `nodes:
+  - name: source
+    code: [104, 21, 99]
+  - name: double
+    program: double.txt
+edges:
+  - from: source
+    to: double
+`,
+		"net.json": `{"nodes": [{"name": "source", "code": [104, 21, 99]}, {"name": "double", "program": "double.txt"}],
+"edges": [{"from": "source", "to": "double"}]}`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			topology, err := LoadTopology(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, err := topology.Network()
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := n.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(results["double"].Outputs, []int{42}) {
+				t.Errorf("outputs %v, want [42]", results["double"].Outputs)
+			}
+		})
+	}
+}
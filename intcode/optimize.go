@@ -0,0 +1,304 @@
+package intcode
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OptimizeStats counts the rewrites of Optimize
+type OptimizeStats struct {
+	// Propagated counts memory reads replaced by the value an earlier instruction of the block wrote
+	Propagated int
+	// Folded counts instructions whose result became a constant
+	Folded int
+	// Simplified counts x*1, x+0, x*0 and their mirrors
+	Simplified int
+	// JumpsRemoved counts jumps that are never taken or go to the next instruction
+	JumpsRemoved int
+}
+
+// Optimize rewrites instructions in place, every instruction keeps its address and length, so the absolute addresses
+// in the program stay valid. Within a basic block, reads of cells an earlier instruction wrote a known value to
+// become immediates, arithmetic on constants is folded into "add result, 0, out", trivial arithmetic is simplified
+// and jumps that are never taken or lead to the next instruction become a never taken "jt 0, 0".
+//
+// Blocks start at the targets of immediate jumps, after every jump, as that is where calls return to, and at the
+// addresses stored in data, which may be jump tables. Instructions with a cell that an operand reads or writes are
+// left alone, as the program uses them as data or rewrites them. Relative operands may reach any cell, so programs
+// with one are returned unchanged. Like Transpile this assumes programs do not compute other jump targets, check the
+// result with VerifyEquivalent.
+func Optimize(program []int) ([]int, OptimizeStats) {
+	var stats OptimizeStats
+	insts := translatable(program)
+	code := make([]bool, len(program))
+	for address, inst := range insts {
+		for i := 0; i < inst.Len(); i++ {
+			code[address+i] = true
+		}
+	}
+
+	// exposed are the cells operands read or write
+	exposed := make(map[int]bool)
+	for _, inst := range insts {
+		for p, param := range inst.Params {
+			switch inst.Modes[p] {
+			case RELATIVE_MODE:
+				return append([]int(nil), program...), stats
+			case POSITION_MODE:
+				exposed[param] = true
+			}
+		}
+	}
+
+	targets := map[int]bool{0: true}
+	volatile := make(map[int]bool)
+	for address, val := range program {
+		// a jump table in data
+		if _, ok := insts[val]; ok && !code[address] {
+			targets[val] = true
+		}
+	}
+	for address, inst := range insts {
+		if inst.Opcode == JMP_IF_TRUE || inst.Opcode == JMP_IF_FALSE {
+			targets[address+inst.Len()] = true
+			if inst.Modes[1] == IMMEDIATE_MODE {
+				targets[inst.Params[1]] = true
+			}
+		}
+		for i := 0; i < inst.Len(); i++ {
+			if exposed[address+i] {
+				// a self-reading or self-modifying program sees the rewritten cells
+				volatile[address] = true
+			}
+		}
+	}
+
+	out := append([]int(nil), program...)
+	known := make(map[int]int)
+	for address := 0; address < len(program); address++ {
+		inst, ok := insts[address]
+		if !ok {
+			continue
+		}
+		if targets[address] || volatile[address] {
+			known = make(map[int]int)
+		}
+		if volatile[address] {
+			address += inst.Len() - 1
+			continue
+		}
+
+		opt := &Instruction{Address: address, Opcode: inst.Opcode, Modes: append([]int(nil), inst.Modes...), Params: append([]int(nil), inst.Params...)}
+		write := writesParam(opt.Opcode)
+		for p := range opt.Params {
+			if p == write || opt.Modes[p] != POSITION_MODE {
+				continue
+			}
+			if val, ok := known[opt.Params[p]]; ok {
+				opt.Modes[p], opt.Params[p] = IMMEDIATE_MODE, val
+				stats.Propagated++
+			}
+		}
+
+		// result is the value written by the instruction when it is a constant
+		var result *int
+		switch opt.Opcode {
+		case ADD, MUL, LESS_THAN, EQUALS:
+			result = optimizeArithmetic(opt, &stats)
+		case JMP_IF_TRUE, JMP_IF_FALSE:
+			optimizeJump(opt, &stats)
+		}
+
+		if write >= 0 {
+			target := opt.Params[write]
+			switch {
+			case target >= 0 && target < len(code) && code[target]:
+				// writes to code change instructions
+				known = make(map[int]int)
+			case result != nil:
+				known[target] = *result
+			default:
+				delete(known, target)
+			}
+		}
+		if opt.Opcode == JMP_IF_TRUE || opt.Opcode == JMP_IF_FALSE || opt.Opcode == HALT {
+			known = make(map[int]int)
+		}
+
+		copy(out[address:], encode(opt))
+		address += inst.Len() - 1
+	}
+	return out, stats
+}
+
+// optimizeArithmetic simplifies the instruction and returns its result when it is a constant
+func optimizeArithmetic(inst *Instruction, stats *OptimizeStats) *int {
+	imm := func(p int) (int, bool) {
+		return inst.Params[p], inst.Modes[p] == IMMEDIATE_MODE
+	}
+	a, aConst := imm(0)
+	b, bConst := imm(1)
+
+	if aConst && bConst {
+		var result int
+		switch inst.Opcode {
+		case ADD:
+			result = a + b
+		case MUL:
+			result = a * b
+		case LESS_THAN:
+			if a < b {
+				result = 1
+			}
+		case EQUALS:
+			if a == b {
+				result = 1
+			}
+		}
+		if inst.Opcode != ADD || b != 0 {
+			stats.Folded++
+		}
+		inst.Opcode = ADD
+		inst.Params[0], inst.Params[1] = result, 0
+		return &result
+	}
+
+	// move keeps operand p and adds zero to it
+	move := func(p int) {
+		stats.Simplified++
+		inst.Modes[0], inst.Params[0] = inst.Modes[p], inst.Params[p]
+		inst.Modes[1], inst.Params[1] = IMMEDIATE_MODE, 0
+		inst.Opcode = ADD
+	}
+	switch {
+	case inst.Opcode == MUL && ((aConst && a == 0) || (bConst && b == 0)):
+		stats.Simplified++
+		inst.Opcode = ADD
+		inst.Modes[0], inst.Params[0] = IMMEDIATE_MODE, 0
+		inst.Modes[1], inst.Params[1] = IMMEDIATE_MODE, 0
+		zero := 0
+		return &zero
+	case inst.Opcode == MUL && bConst && b == 1:
+		move(0)
+	case inst.Opcode == MUL && aConst && a == 1:
+		move(1)
+	case inst.Opcode == ADD && aConst && a == 0:
+		move(1)
+	case (inst.Opcode == LESS_THAN || inst.Opcode == EQUALS) && inst.Modes[0] == inst.Modes[1] && a == b && !aConst:
+		// the same cell compared with itself
+		stats.Folded++
+		result := 0
+		if inst.Opcode == EQUALS {
+			result = 1
+		}
+		inst.Opcode = ADD
+		inst.Modes[0], inst.Params[0] = IMMEDIATE_MODE, result
+		inst.Modes[1], inst.Params[1] = IMMEDIATE_MODE, 0
+		return &result
+	}
+	return nil
+}
+
+// optimizeJump turns jumps that are never taken or lead to the next instruction into a never taken jump, and jumps
+// that are always taken into "jt 1, target"
+func optimizeJump(inst *Instruction, stats *OptimizeStats) {
+	next := inst.Address + inst.Len()
+	cond, condConst := inst.Params[0], inst.Modes[0] == IMMEDIATE_MODE
+	taken := (cond != 0) == (inst.Opcode == JMP_IF_TRUE)
+	nop := inst.Opcode == JMP_IF_TRUE && condConst && cond == 0 && inst.Modes[1] == IMMEDIATE_MODE && inst.Params[1] == 0
+
+	switch {
+	case nop:
+	case (condConst && !taken) || (inst.Modes[1] == IMMEDIATE_MODE && inst.Params[1] == next):
+		stats.JumpsRemoved++
+		inst.Opcode = JMP_IF_TRUE
+		inst.Modes[0], inst.Params[0] = IMMEDIATE_MODE, 0
+		inst.Modes[1], inst.Params[1] = IMMEDIATE_MODE, 0
+	case condConst && inst.Opcode == JMP_IF_FALSE:
+		inst.Opcode = JMP_IF_TRUE
+		inst.Params[0] = 1
+	}
+}
+
+// encode writes the instruction as memory cells
+func encode(inst *Instruction) []int {
+	value := inst.Opcode
+	scale := 100
+	for _, mode := range inst.Modes {
+		value += mode * scale
+		scale *= 10
+	}
+	return append([]int{value}, inst.Params...)
+}
+
+// MismatchError reports a difference between a program and its optimized version
+type MismatchError struct {
+	Input []int
+	Msg   string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("input %v: %s", e.Input, e.Msg)
+}
+
+// VerifyEquivalent runs both programs with every input and compares their outputs, how they stopped and their final
+// memory, except for the cells where the programs differ. Runs stop at the limits, which should include MaxSteps for
+// programs that may not halt.
+func VerifyEquivalent(original, optimized []int, inputs [][]int, limits Limits) error {
+	for _, input := range inputs {
+		a := verifyRun(original, input, limits)
+		b := verifyRun(optimized, input, limits)
+		if a.fault != b.fault {
+			return &MismatchError{input, fmt.Sprintf("fault %q, optimized %q", a.fault, b.fault)}
+		}
+		if !reflect.DeepEqual(a.outputs, b.outputs) {
+			return &MismatchError{input, fmt.Sprintf("outputs %v, optimized %v", a.outputs, b.outputs)}
+		}
+		if a.state != b.state {
+			return &MismatchError{input, fmt.Sprintf("stopped with %s, optimized with %s", a.state, b.state)}
+		}
+		for address := 0; address < len(a.memory) || address < len(b.memory); address++ {
+			if address < len(original) && address < len(optimized) && original[address] != optimized[address] {
+				continue
+			}
+			var x, y int
+			if address < len(a.memory) {
+				x = a.memory[address]
+			}
+			if address < len(b.memory) {
+				y = b.memory[address]
+			}
+			if x != y {
+				return &MismatchError{input, fmt.Sprintf("memory at %d is %d, optimized %d", address, x, y)}
+			}
+		}
+	}
+	return nil
+}
+
+type verifyResult struct {
+	outputs []int
+	memory  []int
+	state   string
+	fault   string
+}
+
+func verifyRun(program, input []int, limits Limits) (r verifyResult) {
+	c := NewComputer(program, WithLimits(limits))
+	c.Send(input...)
+	c.CloseInput()
+	result, err := c.Run()
+	r.outputs = c.TakeOutput()
+	r.memory = c.memory
+	switch result.Reason {
+	case HaltFault:
+		r.fault = fmt.Sprint(err.(*FaultError).Fault)
+	case HaltLimit:
+		r.state = err.Error()
+	case HaltInputEOF:
+		r.state = "input closed"
+	default:
+		r.state = "halted"
+	}
+	return r
+}
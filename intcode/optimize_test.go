@@ -0,0 +1,71 @@
+package intcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOptimizeEquivalent(t *testing.T) {
+	diagnostic, err := LoadProgram("testdata/diagnostic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		program []int
+		inputs  [][]int
+		// want is the optimized program, nil to only compare the behavior
+		want []int
+	}{
+		{"folds and propagates", []int{1101, 2, 3, 11, 1002, 11, 4, 12, 4, 12, 99, 0, 0}, [][]int{nil},
+			[]int{1101, 5, 0, 11, 1101, 20, 0, 12, 104, 20, 99, 0, 0}},
+		{"removes jumps", []int{1106, 1, 10, 1105, 1, 6, 104, 1, 99, 0, 0}, [][]int{nil},
+			[]int{1105, 0, 0, 1105, 0, 0, 104, 1, 99, 0, 0}},
+		{"reads its own operand", []int{1101, 2, 3, 7, 4, 1, 99, 0}, [][]int{nil},
+			[]int{1101, 2, 3, 7, 4, 1, 99, 0}},
+		{"reads its own opcode", []int{1, 0, 0, 9, 4, 9, 99, 0, 0, 0}, [][]int{nil},
+			[]int{1, 0, 0, 9, 4, 9, 99, 0, 0, 0}},
+		{"modifies its own operand", []int{1101, 1, 1, 7, 1101, 6, 0, 0, 4, 0, 99}, [][]int{nil},
+			[]int{1101, 1, 1, 7, 1101, 6, 0, 0, 4, 0, 99}},
+		{"modifies a later instruction", []int{3, 7, 1101, 0, 0, 13, 104, 0, 99, 0, 0, 0, 0, 0}, [][]int{{1}, {2}},
+			[]int{3, 7, 1101, 0, 0, 13, 104, 0, 99, 0, 0, 0, 0, 0}},
+		{"relative operands", quine, [][]int{nil}, quine},
+		{"day 2", []int{1, 9, 10, 3, 2, 3, 11, 0, 99, 30, 40, 50}, [][]int{nil}, nil},
+		{"day 5 compare to 8", compare8, [][]int{{7}, {8}, {9}}, nil},
+		{"day 5 diagnostic", diagnostic, [][]int{{1}, {5}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			optimized, _ := Optimize(tt.program)
+			if tt.want != nil && !reflect.DeepEqual(optimized, tt.want) {
+				t.Errorf("optimized %v, want %v", optimized, tt.want)
+			}
+			if err := VerifyEquivalent(tt.program, optimized, tt.inputs, Limits{MaxSteps: 100000}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestVerifyEquivalent(t *testing.T) {
+	tests := []struct {
+		name                string
+		original, optimized []int
+		equal               bool
+	}{
+		{"same", []int{104, 1, 99}, []int{104, 1, 99}, true},
+		{"output", []int{104, 1, 99}, []int{104, 2, 99}, false},
+		{"stop", []int{3, 0, 99}, []int{3, 0, 3, 0, 99}, false},
+		{"fault", []int{104, 1, 99}, []int{104, 1, 98}, false},
+		{"limit", []int{1105, 1, 0}, []int{1105, 1, 0}, true},
+		{"memory", []int{1101, 1, 1, 5, 99, 0}, []int{1101, 1, 2, 6, 99, 0, 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyEquivalent(tt.original, tt.optimized, [][]int{{1}}, Limits{MaxSteps: 1000})
+			if (err == nil) != tt.equal {
+				t.Errorf("error %v, want equal %v", err, tt.equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package intcode
+
+import (
+	"fmt"
+)
+
+// Access is a kind of memory access counted by a Profile
+type Access int
+
+const (
+	// AccessReads counts the reads through position and relative parameters
+	AccessReads Access = iota
+	// AccessWrites counts the writes through position and relative parameters
+	AccessWrites
+	// AccessExecutes counts the executions of every cell of an instruction, its opcode and its parameters
+	AccessExecutes
+)
+
+func (a Access) String() string {
+	switch a {
+	case AccessReads:
+		return "reads"
+	case AccessWrites:
+		return "writes"
+	case AccessExecutes:
+		return "executes"
+	default:
+		return fmt.Sprintf("Access(%d)", int(a))
+	}
+}
+
+// ParseAccess returns the access kind named reads, writes or executes
+func ParseAccess(name string) (Access, error) {
+	switch name {
+	case "reads":
+		return AccessReads, nil
+	case "writes":
+		return AccessWrites, nil
+	case "executes":
+		return AccessExecutes, nil
+	default:
+		return 0, fmt.Errorf("unknown access %q, want reads, writes or executes", name)
+	}
+}
+
+// Profile counts the reads, writes and executions of every memory cell. It is a Tracer, so several runs can share
+// one, and the profiles of separate runs add up with Merge.
+type Profile struct {
+	Reads    []int `json:"reads"`
+	Writes   []int `json:"writes"`
+	Executes []int `json:"executes"`
+}
+
+// NewProfile creates an empty profile
+func NewProfile() *Profile {
+	return &Profile{}
+}
+
+// Trace counts the accesses of an executed instruction
+func (p *Profile) Trace(c Inspector, e Event) {
+	for i := 0; i <= paramCounts[e.Opcode]; i++ {
+		countAccess(&p.Executes, e.IP+i, 1)
+	}
+	for _, address := range e.Reads {
+		countAccess(&p.Reads, address, 1)
+	}
+	for _, address := range e.Writes {
+		countAccess(&p.Writes, address, 1)
+	}
+}
+
+// countAccess adds n to the count of the address, growing the counts as needed
+func countAccess(counts *[]int, address, n int) {
+	if address < 0 {
+		return
+	}
+	if address >= len(*counts) {
+		size := 2 * len(*counts)
+		if size <= address {
+			size = address + 1
+		}
+		grown := make([]int, size)
+		copy(grown, *counts)
+		*counts = grown
+	}
+	(*counts)[address] += n
+}
+
+// Merge adds the counts of other
+func (p *Profile) Merge(other *Profile) {
+	for address, n := range other.Reads {
+		countAccess(&p.Reads, address, n)
+	}
+	for address, n := range other.Writes {
+		countAccess(&p.Writes, address, n)
+	}
+	for address, n := range other.Executes {
+		countAccess(&p.Executes, address, n)
+	}
+}
+
+// Counts returns the counts of one kind of access for every address up to the highest one accessed in any way
+func (p *Profile) Counts(access Access) []int {
+	var counts []int
+	switch access {
+	case AccessReads:
+		counts = p.Reads
+	case AccessWrites:
+		counts = p.Writes
+	default:
+		counts = p.Executes
+	}
+	out := make([]int, p.Size())
+	copy(out, counts)
+	return out
+}
+
+// Size returns one more than the highest address accessed
+func (p *Profile) Size() int {
+	size := 0
+	for _, counts := range [][]int{p.Reads, p.Writes, p.Executes} {
+		for address := len(counts) - 1; address >= size; address-- {
+			if counts[address] != 0 {
+				size = address + 1
+				break
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,113 @@
+package intcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+// profiled runs the program under a profile
+func profiled(t *testing.T, program []int, input ...int) *Profile {
+	t.Helper()
+	p := NewProfile()
+	c := NewComputer(program, WithTracer(p))
+	c.Send(input...)
+	c.CloseInput()
+	if _, err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		input   []int
+		// reads, writes and executes are the counts of every address
+		reads, writes, executes []int
+	}{
+		{"add in place", []int{1001, 5, 2, 5, 99, 0}, nil,
+			[]int{0, 0, 0, 0, 0, 1}, []int{0, 0, 0, 0, 0, 1}, []int{1, 1, 1, 1, 1, 0}},
+		{"immediate", []int{1101, 2, 3, 5, 99, 0}, nil,
+			[]int{0, 0, 0, 0, 0, 0}, []int{0, 0, 0, 0, 0, 1}, []int{1, 1, 1, 1, 1, 0}},
+		{"echo", []int{3, 5, 4, 5, 99, 0}, []int{7},
+			[]int{0, 0, 0, 0, 0, 1}, []int{0, 0, 0, 0, 0, 1}, []int{1, 1, 1, 1, 1, 0}},
+		{"loop", []int{1001, 12, 1, 12, 1007, 12, 3, 13, 1005, 13, 0, 99, 0, 0}, nil,
+			[]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 6, 3},
+			[]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 3, 3},
+			[]int{3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 1, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := profiled(t, tt.program, tt.input...)
+			for _, access := range []struct {
+				access Access
+				want   []int
+			}{{AccessReads, tt.reads}, {AccessWrites, tt.writes}, {AccessExecutes, tt.executes}} {
+				if got := p.Counts(access.access); !reflect.DeepEqual(got, access.want) {
+					t.Errorf("%s %v, want %v", access.access, got, access.want)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileMerge(t *testing.T) {
+	short := profiled(t, []int{1101, 2, 3, 5, 99, 0})
+	long := profiled(t, []int{1001, 12, 1, 12, 1007, 12, 3, 13, 1005, 13, 0, 99, 0, 0})
+	tests := []struct {
+		name  string
+		into  *Profile
+		other *Profile
+		want  Profile
+	}{
+		{"into empty", NewProfile(), short, Profile{
+			Writes:   []int{0, 0, 0, 0, 0, 1},
+			Executes: []int{1, 1, 1, 1, 1}}},
+		{"grows", profiled(t, []int{1101, 2, 3, 5, 99, 0}), long, Profile{
+			Reads:    []int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 6, 3},
+			Writes:   []int{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 3, 3},
+			Executes: []int{4, 4, 4, 4, 4, 3, 3, 3, 3, 3, 3, 1}}},
+		{"empty other", profiled(t, []int{1101, 2, 3, 5, 99, 0}), NewProfile(), Profile{
+			Writes:   []int{0, 0, 0, 0, 0, 1},
+			Executes: []int{1, 1, 1, 1, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.into.Merge(tt.other)
+			for _, access := range []Access{AccessReads, AccessWrites, AccessExecutes} {
+				want := (&tt.want).Counts(access)
+				if got := tt.into.Counts(access); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s %v, want %v", access, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Access
+		wantErr bool
+	}{
+		{"reads", AccessReads, false},
+		{"writes", AccessWrites, false},
+		{"executes", AccessExecutes, false},
+		{"all", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAccess(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error %v", tt.name, err)
+			continue
+		}
+		if err == nil && (got != tt.want || got.String() != tt.name) {
+			t.Errorf("%q: parsed %v", tt.name, got)
+		}
+	}
+	if got := Access(7).String(); got != "Access(7)" {
+		t.Errorf("unknown access is %q", got)
+	}
+}
@@ -0,0 +1,107 @@
+package intcode
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HaltReason is why Run returned
+type HaltReason int
+
+const (
+	// HaltOpcode means the program executed the HALT instruction
+	HaltOpcode HaltReason = iota
+	// HaltInputEOF means an INPUT instruction found the input closed
+	HaltInputEOF
+	// HaltWaiting means the computer suspended on an INPUT instruction, running it again retries the input
+	HaltWaiting
+	// HaltLimit means the computer exceeded a limit or violated its spec, the error is a LimitError or SpecError
+	HaltLimit
+	// HaltFault means an instruction could not be executed, the error is a FaultError
+	HaltFault
+)
+
+func (r HaltReason) String() string {
+	switch r {
+	case HaltOpcode:
+		return "halt"
+	case HaltInputEOF:
+		return "input EOF"
+	case HaltWaiting:
+		return "waiting"
+	case HaltLimit:
+		return "limit"
+	case HaltFault:
+		return "fault"
+	default:
+		return fmt.Sprintf("HaltReason(%d)", int(r))
+	}
+}
+
+// RunResult describes how a run ended and what it did
+type RunResult struct {
+	Reason HaltReason
+	// Steps and Outputs count the instructions executed and values output by this run
+	Steps   int
+	Outputs int
+	// MaxAddress and MaxRelativeBase are the highest memory address accessed and relative base set since the
+	// computer was created
+	MaxAddress      int
+	MaxRelativeBase int
+	Duration        time.Duration
+}
+
+func (r RunResult) String() string {
+	return fmt.Sprintf("%s after %d steps and %d outputs in %v, max address %d, max relative base %d",
+		r.Reason, r.Steps, r.Outputs, r.Duration, r.MaxAddress, r.MaxRelativeBase)
+}
+
+// FaultError is the termination reason of a computer that could not execute an instruction, like an unknown opcode
+// or an OverflowError. The instruction pointer is left on the instruction.
+type FaultError struct {
+	IP    int
+	Steps int
+	// Fault is the value the instruction panicked with
+	Fault interface{}
+}
+
+func (e *FaultError) Error() string {
+	return fmt.Sprintf("fault at instruction %d after %d steps: %v", e.IP, e.Steps, e.Fault)
+}
+
+// Unwrap returns the fault when it is an error, like an OverflowError
+func (e *FaultError) Unwrap() error {
+	err, _ := e.Fault.(error)
+	return err
+}
+
+// result summarizes the run that started at the time after the given number of steps and outputs
+func (c *Machine[W, A]) result(start time.Time, steps, outputs int, err error) RunResult {
+	r := RunResult{
+		Steps:           c.steps - steps,
+		Outputs:         c.outputs - outputs,
+		MaxAddress:      c.maxAddress,
+		MaxRelativeBase: c.maxRelativeBase,
+		Duration:        time.Since(start),
+	}
+	var fault *FaultError
+	switch {
+	case errors.As(err, &fault):
+		r.Reason = HaltFault
+	case err != nil:
+		r.Reason = HaltLimit
+	case c.inputClosed:
+		r.Reason = HaltInputEOF
+	case c.waiting:
+		r.Reason = HaltWaiting
+	}
+	return r
+}
+
+// recoverFault turns a fault into a FaultError, it must be deferred
+func (c *Machine[W, A]) recoverFault(err *error) {
+	if r := recover(); r != nil {
+		*err = &FaultError{IP: c.ip, Steps: c.steps, Fault: r}
+	}
+}
@@ -0,0 +1,90 @@
+package intcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		opts    []Option
+		input   []int
+		close   bool
+		want    RunResult
+		wantErr bool
+	}{
+		{"halt", []int{104, 1, 104, 2, 99}, nil, nil, false,
+			RunResult{Reason: HaltOpcode, Steps: 3, Outputs: 2, MaxAddress: 4}, false},
+		{"input EOF", []int{3, 5, 1105, 1, 0, 0}, nil, []int{1, 2}, true,
+			RunResult{Reason: HaltInputEOF, Steps: 4, MaxAddress: 5}, false},
+		{"waiting", []int{3, 5, 1105, 1, 0, 0}, []Option{WithInputPolicy(InputSuspend)}, []int{1}, false,
+			RunResult{Reason: HaltWaiting, Steps: 2, MaxAddress: 5}, false},
+		{"limit", []int{1105, 1, 0}, []Option{WithLimits(Limits{MaxSteps: 10})}, nil, false,
+			RunResult{Reason: HaltLimit, Steps: 10, MaxAddress: 2}, true},
+		{"fault", []int{104, 7, 42}, nil, nil, false,
+			RunResult{Reason: HaltFault, Steps: 1, Outputs: 1, MaxAddress: 2}, true},
+		{"relative base", []int{109, 50, 109, -20, 204, 10, 99}, nil, nil, false,
+			RunResult{Reason: HaltOpcode, Steps: 4, Outputs: 1, MaxAddress: 40, MaxRelativeBase: 50}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComputer(tt.program, tt.opts...)
+			c.Send(tt.input...)
+			if tt.close {
+				c.CloseInput()
+			}
+			result, err := c.Run()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v", err)
+			}
+			if result.Duration < 0 {
+				t.Errorf("run took %v", result.Duration)
+			}
+			result.Duration = 0
+			if result != tt.want {
+				t.Errorf("result %+v, want %+v", result, tt.want)
+			}
+			if !strings.HasPrefix(result.String(), tt.want.Reason.String()+" after ") {
+				t.Errorf("result text %q", result.String())
+			}
+		})
+	}
+}
+
+func TestRunResultCountsThisRun(t *testing.T) {
+	// outputs every input until the input closes
+	c := NewComputer([]int{3, 7, 4, 7, 1105, 1, 0, 0}, WithInputPolicy(InputSuspend))
+	c.Send(1, 2)
+	first, _ := c.Run()
+	c.Send(3)
+	c.CloseInput()
+	second, _ := c.Run()
+	if first.Reason != HaltWaiting || first.Steps != 6 || first.Outputs != 2 {
+		t.Errorf("first run %v", first)
+	}
+	if second.Reason != HaltInputEOF || second.Steps != 3 || second.Outputs != 1 {
+		t.Errorf("second run %v", second)
+	}
+}
+
+func TestFaultError(t *testing.T) {
+	words, err := Words[int32, Int32Words]([]int{1102, 100000, 100000, 0, 99})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := NewMachine[int32, Int32Words](words).Run()
+	var fault *FaultError
+	if result.Reason != HaltFault || !errors.As(err, &fault) || fault.IP != 0 || fault.Steps != 0 {
+		t.Fatalf("stopped with %v %v", result.Reason, err)
+	}
+	var overflow *OverflowError
+	if !errors.As(err, &overflow) || overflow.Word != "int32" {
+		t.Errorf("fault %v does not unwrap to the overflow", err)
+	}
+	if _, err := NewComputer([]int{42}).Run(); errors.Unwrap(err) != nil {
+		t.Errorf("a fault that is no error unwraps to %v", errors.Unwrap(err))
+	}
+}
@@ -0,0 +1,195 @@
+package intcode
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+)
+
+// ErrRouterIdle is returned when the network went idle and no idle handler sent a packet
+var ErrRouterIdle = errors.New("router idle with no packets in flight")
+
+// Packet is an (X, Y) pair sent from one address to another
+type Packet struct {
+	From, To int
+	X, Y     int
+}
+
+// Link identifies the packets sent from one address to another
+type Link struct {
+	From, To int
+}
+
+// LinkStats counts the packets sent over a link
+type LinkStats struct {
+	// Delivered packets were queued on a node or passed to a handler
+	Delivered int
+	// Dropped packets were addressed to an unknown address
+	Dropped int
+}
+
+// PacketHandler receives the packets sent to a special address. It returns false to stop the router.
+type PacketHandler func(p Packet) bool
+
+// Router runs a network of computers that talk with packets. Every node boots with its address as the first input,
+// then outputs packets as (destination, X, Y) triples and reads the packets sent to it as X, Y pairs. A node reads
+// the default input, -1, while its queue is empty.
+type Router struct {
+	// IdleThreshold is the number of consecutive default inputs after which a node with nothing to do counts as idle
+	IdleThreshold int
+
+	s        *Scheduler
+	nodes    []*Process
+	handlers map[int]PacketHandler
+	idle     func() bool
+	stats    map[Link]*LinkStats
+
+	wasIdle bool
+	err     error
+}
+
+// NewRouter boots count computers running the program with the addresses 0 to count-1. The options apply to every
+// node, the router itself logs dropped packets to the logger of WithLogger.
+func NewRouter(memory []int, count int, opts ...Option) *Router {
+	r := &Router{
+		IdleThreshold: 2,
+		s:             &Scheduler{Quantum: 200, Logger: loggerOf(opts)},
+		handlers:      make(map[int]PacketHandler),
+		stats:         make(map[Link]*LinkStats),
+	}
+	r.s.AfterTurn = r.afterTurn
+	r.s.OnStop = r.onStop
+
+	opts = append([]Option{WithDefaultInput(-1)}, opts...)
+	for addr := 0; addr < count; addr++ {
+		addr := addr
+		var pending []int
+		node := r.s.Spawn(memory, func(val int) {
+			pending = append(pending, val)
+			if len(pending) == 3 {
+				r.route(Packet{From: addr, To: pending[0], X: pending[1], Y: pending[2]})
+				pending = pending[:0]
+			}
+		}, opts...)
+		node.Send(addr)
+		r.nodes = append(r.nodes, node)
+	}
+	return r
+}
+
+// Handle registers the handler for packets sent to the address. Handlers take precedence over nodes.
+func (r *Router) Handle(addr int, handler PacketHandler) {
+	r.handlers[addr] = handler
+}
+
+// OnIdle registers a handler that is called when every node is idle and no packet is queued. It may inject packets
+// with Send and returns false to stop the router.
+func (r *Router) OnIdle(handler func() bool) {
+	r.idle = handler
+}
+
+// Send queues a packet on the node with the address. The packet is not counted in the link statistics.
+func (r *Router) Send(to, x, y int) {
+	if to < 0 || to >= len(r.nodes) {
+		r.s.log().Warn("dropping packet for unknown address", zap.Int("to", to))
+		return
+	}
+	r.nodes[to].Send(x, y)
+	r.wasIdle = false
+}
+
+// Run routes packets until a handler stops the router, every node stopped, the network stays idle or a node is
+// killed by a limit or a fault, which returns the error of the node
+func (r *Router) Run() error {
+	r.err = nil
+	r.s.Run()
+	return r.err
+}
+
+// Stats returns the packet counts of every link that carried a packet
+func (r *Router) Stats() map[Link]LinkStats {
+	stats := make(map[Link]LinkStats, len(r.stats))
+	for link, s := range r.stats {
+		stats[link] = *s
+	}
+	return stats
+}
+
+func (r *Router) route(p Packet) {
+	link := Link{From: p.From, To: p.To}
+	stats, ok := r.stats[link]
+	if !ok {
+		stats = &LinkStats{}
+		r.stats[link] = stats
+	}
+
+	if handler, ok := r.handlers[p.To]; ok {
+		stats.Delivered++
+		if !handler(p) {
+			r.s.Stop()
+		}
+		return
+	}
+	if p.To < 0 || p.To >= len(r.nodes) {
+		r.s.log().Debug("dropping packet", zap.Int("from", p.From), zap.Int("to", p.To))
+		stats.Dropped++
+		return
+	}
+	stats.Delivered++
+	r.nodes[p.To].Send(p.X, p.Y)
+}
+
+// onStop stops the router when a node was killed
+func (r *Router) onStop(p *Process) {
+	if p.Err() != nil && r.err == nil {
+		r.err = fmt.Errorf("node %d: %w", p.ID, p.Err())
+		r.s.Stop()
+	}
+}
+
+// afterTurn runs the idle handler when the network goes idle
+func (r *Router) afterTurn(p *Process) {
+	// a handler or a killed node already stopped the router
+	if r.err != nil || r.s.stopping {
+		return
+	}
+	if !r.isIdle() {
+		r.wasIdle = false
+		return
+	}
+	if r.wasIdle {
+		return
+	}
+	r.wasIdle = true
+
+	if r.idle == nil {
+		r.err = ErrRouterIdle
+		r.s.Stop()
+		return
+	}
+	if !r.idle() {
+		r.s.Stop()
+		return
+	}
+	if r.wasIdle {
+		r.err = ErrRouterIdle
+		r.s.Stop()
+	}
+}
+
+// isIdle reports whether no node has queued input and every running node kept reading the default input. A network
+// without running nodes is not idle, it is done.
+func (r *Router) isIdle() bool {
+	running := 0
+	for _, node := range r.nodes {
+		if node.stopped() {
+			continue
+		}
+		if len(node.c.inQueue) > 0 || node.c.ConsecutiveDefaults() < r.IdleThreshold {
+			return false
+		}
+		running++
+	}
+	// a network whose nodes all stopped is done, not idle
+	return running > 0
+}
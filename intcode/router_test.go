@@ -0,0 +1,122 @@
+package intcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	// sender sends a packet with its address and 9 to the next address, then polls its input forever
+	sender = []int{3, 50, 1001, 50, 1, 51, 4, 51, 4, 50, 104, 9, 3, 52, 1105, 1, 12}
+	// poller reads its address, then polls its input forever
+	poller = []int{3, 50, 3, 51, 1105, 1, 2}
+)
+
+func TestRouterPackets(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		handle  bool
+		wantErr error
+		stats   map[Link]LinkStats
+	}{
+		{"handled", 3, true, nil, map[Link]LinkStats{
+			{From: 0, To: 1}: {Delivered: 1},
+			{From: 1, To: 2}: {Delivered: 1},
+			{From: 2, To: 3}: {Delivered: 1},
+		}},
+		{"dropped", 2, false, ErrRouterIdle, map[Link]LinkStats{
+			{From: 0, To: 1}: {Delivered: 1},
+			{From: 1, To: 2}: {Dropped: 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter(sender, tt.count)
+			var handled []Packet
+			if tt.handle {
+				r.Handle(tt.count, func(p Packet) bool {
+					handled = append(handled, p)
+					return false
+				})
+			}
+			if err := r.Run(); err != tt.wantErr {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(r.Stats(), tt.stats) {
+				t.Errorf("stats %v, want %v", r.Stats(), tt.stats)
+			}
+			if tt.handle && !reflect.DeepEqual(handled, []Packet{{From: 2, To: 3, X: 2, Y: 9}}) {
+				t.Errorf("handled %v", handled)
+			}
+		})
+	}
+}
+
+func TestRouterIdle(t *testing.T) {
+	r := NewRouter(poller, 2)
+	if err := r.Run(); err != ErrRouterIdle {
+		t.Fatalf("error %v, want ErrRouterIdle", err)
+	}
+
+	r = NewRouter(poller, 2)
+	calls := 0
+	r.OnIdle(func() bool {
+		calls++
+		if calls == 1 {
+			r.Send(0, 1, 2)
+			return true
+		}
+		return false
+	})
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("idle handler called %d times, want 2", calls)
+	}
+	if served := r.nodes[0].Computer().DefaultsServed(); served < r.IdleThreshold {
+		t.Errorf("node 0 served %d defaults, want at least %d", served, r.IdleThreshold)
+	}
+}
+
+func TestRouterIdleHandlerWithoutPackets(t *testing.T) {
+	// a handler that keeps the router running without sending anything does not make it spin
+	r := NewRouter(poller, 2)
+	r.OnIdle(func() bool {
+		return true
+	})
+	if err := r.Run(); err != ErrRouterIdle {
+		t.Errorf("error %v, want ErrRouterIdle", err)
+	}
+}
+
+func TestRouterStops(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		wantErr error
+		stats   map[Link]LinkStats
+	}{
+		// reads its address and halts
+		{"halt", []int{3, 10, 99}, nil, map[Link]LinkStats{}},
+		// sends a packet with its address and 9 to the next address, then halts
+		{"send and halt", []int{3, 50, 1001, 50, 1, 51, 4, 51, 4, 50, 104, 9, 99}, nil, map[Link]LinkStats{
+			{From: 0, To: 1}: {Delivered: 1},
+			{From: 1, To: 2}: {Dropped: 1},
+		}},
+		// halts at address 0 and polls its input everywhere else
+		{"one keeps polling", []int{3, 50, 1005, 50, 6, 99, 3, 51, 1105, 1, 6}, ErrRouterIdle, map[Link]LinkStats{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter(tt.program, 2)
+			if err := r.Run(); err != tt.wantErr {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(r.Stats(), tt.stats) {
+				t.Errorf("stats %v, want %v", r.Stats(), tt.stats)
+			}
+		})
+	}
+}
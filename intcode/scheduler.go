@@ -0,0 +1,215 @@
+package intcode
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+)
+
+// ProcessState is the scheduling state of a Process
+type ProcessState int
+
+const (
+	// ProcessRunnable means the process can execute instructions
+	ProcessRunnable ProcessState = iota
+	// ProcessWaiting means the process is suspended on an INPUT instruction with an empty queue
+	ProcessWaiting
+	// ProcessHalted means the program executed the HALT instruction
+	ProcessHalted
+	// ProcessInputClosed means the program wanted input after its input was closed
+	ProcessInputClosed
+	// ProcessKilled means the program exceeded one of its limits or faulted, see Process.Err
+	ProcessKilled
+)
+
+func (s ProcessState) String() string {
+	switch s {
+	case ProcessRunnable:
+		return "runnable"
+	case ProcessWaiting:
+		return "waiting"
+	case ProcessHalted:
+		return "halted"
+	case ProcessInputClosed:
+		return "input closed"
+	case ProcessKilled:
+		return "killed"
+	default:
+		return fmt.Sprintf("ProcessState(%d)", int(s))
+	}
+}
+
+// Scheduler runs many computers cooperatively on the calling goroutine. Processes take turns in the order they
+// became runnable, each until it waits for input, stops or uses up its quantum, so a run is fully deterministic.
+type Scheduler struct {
+	// Quantum is the number of instructions a process may execute before the next one gets a turn. Zero lets each
+	// process run until it waits for input, reads the default input or stops.
+	Quantum int
+	// OnStop is called once for every process that halts, finds its input closed or is killed
+	OnStop func(p *Process)
+	// AfterTurn is called whenever a process finished its turn
+	AfterTurn func(p *Process)
+	// Logger gets killed processes as warnings and the other process events at debug level, nothing is logged when nil
+	Logger *zap.Logger
+
+	procs    []*Process
+	runQueue []*Process
+	stopping bool
+}
+
+// Process is a computer owned by a Scheduler. Its input is a queue filled with Send.
+type Process struct {
+	// ID is the spawn order of the process, starting at zero
+	ID int
+
+	s      *Scheduler
+	c      *Computer
+	state  ProcessState
+	output func(int)
+}
+
+// Spawn creates a runnable process for the program. The values the program outputs during a turn are passed to
+// output at the end of the turn, on the scheduler goroutine, and output may Send to other processes. With the
+// InputDefault policy the process never waits and keeps its turn until the quantum runs out, or without a quantum
+// until it reads the default input.
+func (s *Scheduler) Spawn(memory []int, output func(val int), opts ...Option) *Process {
+	p := &Process{ID: len(s.procs), s: s, output: output}
+	p.c = NewComputer(memory, opts...)
+	s.procs = append(s.procs, p)
+	s.runQueue = append(s.runQueue, p)
+	return p
+}
+
+// Processes returns every spawned process in spawn order
+func (s *Scheduler) Processes() []*Process {
+	return s.procs
+}
+
+// Run executes runnable processes until none is left or Stop is called. It returns the processes waiting for input,
+// which can be resumed by sending them input and calling Run again.
+func (s *Scheduler) Run() []*Process {
+	s.stopping = false
+	for len(s.runQueue) > 0 && !s.stopping {
+		p := s.runQueue[0]
+		s.runQueue[0] = nil
+		s.runQueue = s.runQueue[1:]
+		p.run()
+		if s.AfterTurn != nil {
+			s.AfterTurn(p)
+		}
+	}
+
+	var waiting []*Process
+	for _, p := range s.procs {
+		if p.state == ProcessWaiting {
+			waiting = append(waiting, p)
+		}
+	}
+	return waiting
+}
+
+// Stop makes Run return once the current turn is over. Runnable processes keep their place and continue on the next Run.
+func (s *Scheduler) Stop() {
+	s.stopping = true
+}
+
+// State returns the scheduling state of the process
+func (p *Process) State() ProcessState {
+	return p.state
+}
+
+// Computer returns the computer run by the process. It must only be inspected between calls to Run.
+func (p *Process) Computer() *Computer {
+	return p.c
+}
+
+// IP returns the instruction pointer of the process. A waiting process points at its INPUT instruction.
+func (p *Process) IP() int {
+	return p.c.IP()
+}
+
+// Send queues values on the input of the process and makes it runnable. Values sent to a stopped process are dropped.
+func (p *Process) Send(values ...int) {
+	if p.stopped() {
+		p.s.log().Debug("dropping values for stopped process", zap.Int("process", p.ID), zap.Ints("values", values))
+		return
+	}
+	p.c.Send(values...)
+	p.wake()
+}
+
+// CloseInput marks the input as closed. The program stops once it wants input after the queue drained.
+func (p *Process) CloseInput() {
+	p.c.CloseInput()
+	p.wake()
+}
+
+// Err returns the LimitError, SpecError or FaultError of a killed process, or nil
+func (p *Process) Err() error {
+	return p.c.Err()
+}
+
+func (p *Process) stopped() bool {
+	return p.state == ProcessHalted || p.state == ProcessInputClosed || p.state == ProcessKilled
+}
+
+func (p *Process) wake() {
+	if p.state == ProcessWaiting {
+		p.state = ProcessRunnable
+		p.s.runQueue = append(p.s.runQueue, p)
+	}
+}
+
+// turn executes instructions until the computer stops or the quantum runs out. A fault is returned as a FaultError.
+func (p *Process) turn() (stop bool, err error) {
+	defer p.c.recoverFault(&err)
+	for i := 0; !stop && (p.s.Quantum == 0 || i < p.s.Quantum); i++ {
+		served := p.c.DefaultsServed()
+		stop = p.c.E()
+		// without a quantum a process polling its input would never give up its turn
+		if p.s.Quantum == 0 && p.c.DefaultsServed() > served {
+			break
+		}
+	}
+	return stop, nil
+}
+
+// run executes a single turn of the process. A fault kills the process like an exceeded limit.
+func (p *Process) run() {
+	stop, err := p.turn()
+	if err != nil {
+		p.c.err = err
+		stop = true
+	}
+	for _, val := range p.c.TakeOutput() {
+		if p.output != nil {
+			p.output(val)
+		}
+	}
+
+	switch {
+	case !stop:
+		// the quantum ran out, go to the back of the queue
+		p.s.runQueue = append(p.s.runQueue, p)
+	case p.c.Err() != nil:
+		p.s.log().Warn("process killed", zap.Int("process", p.ID), zap.Error(p.c.Err()))
+		p.state = ProcessKilled
+	case p.c.Waiting():
+		p.state = ProcessWaiting
+	case p.c.InputClosed():
+		p.state = ProcessInputClosed
+	default:
+		p.state = ProcessHalted
+	}
+
+	if p.stopped() {
+		p.s.log().Debug("process stopped", zap.Int("process", p.ID), zap.Stringer("state", p.state))
+		if p.s.OnStop != nil {
+			p.s.OnStop(p)
+		}
+	}
+}
+
+// log returns the logger of the scheduler
+func (s *Scheduler) log() *zap.Logger {
+	return orNop(s.Logger)
+}
@@ -0,0 +1,163 @@
+package intcode
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSchedulerStates(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		send    []int
+		close   bool
+		want    ProcessState
+		outputs []int
+	}{
+		{"halt", []int{104, 7, 99}, nil, false, ProcessHalted, []int{7}},
+		{"echo", []int{3, 9, 4, 9, 1105, 1, 0, 99, 0, 0}, []int{1, 2}, false, ProcessWaiting, []int{1, 2}},
+		{"input closed", []int{3, 9, 4, 9, 1105, 1, 0, 99, 0, 0}, []int{5}, true, ProcessInputClosed, []int{5}},
+		{"fault", []int{104, 1, 42, 0, 0, 0}, nil, false, ProcessKilled, []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Scheduler{Quantum: 3}
+			var outputs []int
+			p := s.Spawn(tt.program, func(val int) {
+				outputs = append(outputs, val)
+			})
+			p.Send(tt.send...)
+			if tt.close {
+				p.CloseInput()
+			}
+			s.Run()
+			if p.State() != tt.want {
+				t.Errorf("state %v, want %v", p.State(), tt.want)
+			}
+			if !reflect.DeepEqual(outputs, tt.outputs) {
+				t.Errorf("outputs %v, want %v", outputs, tt.outputs)
+			}
+		})
+	}
+}
+
+func TestSchedulerFaultKillsOnlyTheProcess(t *testing.T) {
+	s := &Scheduler{Quantum: 1}
+	var stopped []int
+	s.OnStop = func(p *Process) {
+		stopped = append(stopped, p.ID)
+	}
+	bad := s.Spawn([]int{42, 0, 0, 0}, nil)
+	var outputs []int
+	good := s.Spawn([]int{104, 1, 104, 2, 99}, func(val int) {
+		outputs = append(outputs, val)
+	})
+	s.Run()
+
+	var fault *FaultError
+	if !errors.As(bad.Err(), &fault) || fault.IP != 0 {
+		t.Fatalf("faulting process error %v, want a FaultError at instruction 0", bad.Err())
+	}
+	if bad.State() != ProcessKilled || good.State() != ProcessHalted {
+		t.Errorf("states %v and %v, want killed and halted", bad.State(), good.State())
+	}
+	if !reflect.DeepEqual(outputs, []int{1, 2}) || !reflect.DeepEqual(stopped, []int{0, 1}) {
+		t.Errorf("outputs %v and stop order %v", outputs, stopped)
+	}
+	// a killed computer does not execute anymore
+	if !bad.Computer().E() {
+		t.Errorf("killed computer executed again")
+	}
+}
+
+func TestSchedulerTurnOrder(t *testing.T) {
+	// every process outputs its ID three times
+	program := []int{4, 10, 4, 10, 4, 10, 99}
+	tests := []struct {
+		quantum int
+		want    []int
+	}{
+		{0, []int{0, 0, 0, 1, 1, 1}},
+		{1, []int{0, 1, 0, 1, 0, 1}},
+		{2, []int{0, 0, 1, 1, 0, 1}},
+	}
+	for _, tt := range tests {
+		s := &Scheduler{Quantum: tt.quantum}
+		var outputs []int
+		for id := 0; id < 2; id++ {
+			memory := append(append([]int(nil), program...), 0, 0, 0, id)
+			s.Spawn(memory, func(val int) {
+				outputs = append(outputs, val)
+			})
+		}
+		s.Run()
+		if !reflect.DeepEqual(outputs, tt.want) {
+			t.Errorf("quantum %d: outputs %v, want %v", tt.quantum, outputs, tt.want)
+		}
+	}
+}
+
+func TestNetworkFault(t *testing.T) {
+	n := NewNetwork()
+	if err := n.AddNode("source", []int{104, 1, 99}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.AddNode("bad", []int{42, 0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Connect("source", "bad"); err != nil {
+		t.Fatal(err)
+	}
+	results, err := n.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := results["bad"]
+	var fault *FaultError
+	if bad.State != NodeKilled || !errors.As(bad.Err, &fault) {
+		t.Errorf("faulting node %v with error %v, want killed with a FaultError", bad.State, bad.Err)
+	}
+	if results["source"].State != NodeHalted {
+		t.Errorf("source %v, want halted", results["source"].State)
+	}
+}
+
+func TestRouterFault(t *testing.T) {
+	// every node reads its address and faults
+	r := NewRouter([]int{3, 100, 42}, 3)
+	err := r.Run()
+	var fault *FaultError
+	if !errors.As(err, &fault) || fault.IP != 2 {
+		t.Errorf("router error %v, want a FaultError at instruction 2", err)
+	}
+}
+
+func TestSchedulerDefaultInputWithoutQuantum(t *testing.T) {
+	// poll reads until it gets something other than -1 and outputs it, send gives it a value
+	poll := []int{3, 20, 1008, 20, -1, 21, 1005, 21, 0, 4, 20, 99}
+	tests := []struct {
+		quantum int
+	}{
+		{0},
+		{1},
+		{50},
+	}
+	for _, tt := range tests {
+		s := &Scheduler{Quantum: tt.quantum}
+		var outputs []int
+		poller := s.Spawn(poll, func(val int) {
+			outputs = append(outputs, val)
+		}, WithDefaultInput(-1))
+		s.Spawn([]int{104, 7, 99}, func(val int) {
+			poller.Send(val)
+		})
+		s.Run()
+		if poller.State() != ProcessHalted || !reflect.DeepEqual(outputs, []int{7}) {
+			t.Errorf("quantum %d: poller %v with outputs %v, want halted with [7]", tt.quantum, poller.State(), outputs)
+		}
+		if poller.Computer().DefaultsServed() == 0 {
+			t.Errorf("quantum %d: no default input served", tt.quantum)
+		}
+	}
+}
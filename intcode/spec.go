@@ -0,0 +1,101 @@
+package intcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSpecViolation is matched by errors.Is when a program used a feature its spec level does not have
+var ErrSpecViolation = errors.New("spec violation")
+
+// Spec is a stage of the intcode specification, see WithSpec
+type Spec int
+
+const (
+	// SpecDay09 is the complete specification with relative mode and memory beyond the program, day11 uses it too
+	SpecDay09 Spec = iota
+	// SpecDay02 only has ADD, MUL and HALT in position mode, every instruction is four cells wide
+	SpecDay02
+	// SpecDay05 adds input, output, jumps, comparisons and immediate mode
+	SpecDay05
+)
+
+func (s Spec) String() string {
+	switch s {
+	case SpecDay02:
+		return "day02"
+	case SpecDay05:
+		return "day05"
+	case SpecDay09:
+		return "day09"
+	default:
+		return fmt.Sprintf("Spec(%d)", int(s))
+	}
+}
+
+// ParseSpec returns the spec level named day02, day05, day09 or day11
+func ParseSpec(name string) (Spec, error) {
+	switch name {
+	case "day02":
+		return SpecDay02, nil
+	case "day05":
+		return SpecDay05, nil
+	case "day09", "day11":
+		return SpecDay09, nil
+	default:
+		return 0, fmt.Errorf("unknown spec level %q, want day02, day05, day09 or day11", name)
+	}
+}
+
+// WithSpec restricts the computer to the features of an earlier stage of the specification. Before day09 memory is
+// only the program itself. A program that uses anything else stops with a SpecError.
+func WithSpec(spec Spec) Option {
+	return func(o *options) {
+		o.spec = spec
+	}
+}
+
+// SpecError is the termination reason of a computer that ran an instruction its spec level does not have. The
+// instruction pointer is left on the instruction that was not executed.
+type SpecError struct {
+	Spec  Spec
+	IP    int
+	Steps int
+	Msg   string
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("%v: %s is not part of %s, at instruction %d after %d steps", ErrSpecViolation, e.Msg, e.Spec, e.IP, e.Steps)
+}
+
+// Unwrap returns ErrSpecViolation
+func (e *SpecError) Unwrap() error {
+	return ErrSpecViolation
+}
+
+// violate aborts the current instruction with a spec violation
+func (c *Machine[W, A]) violate(format string, args ...interface{}) {
+	panic(limitExceeded{&SpecError{Spec: c.spec, IP: c.ip, Steps: c.steps, Msg: fmt.Sprintf(format, args...)}})
+}
+
+// checkSpec runs before every instruction of computers with an earlier spec level
+func (c *Machine[W, A]) checkSpec(value, opcode int) {
+	switch c.spec {
+	case SpecDay02:
+		if opcode != ADD && opcode != MUL && opcode != HALT {
+			c.violate("opcode %d", opcode)
+		}
+		if value/100 != 0 {
+			c.violate("parameter mode in %d", value)
+		}
+	case SpecDay05:
+		if opcode == ADJ_RELATIVE_BASE {
+			c.violate("opcode %d", opcode)
+		}
+		for mode := value / 100; mode > 0; mode /= 10 {
+			if mode%10 == RELATIVE_MODE {
+				c.violate("relative mode in %d", value)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package intcode
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    Spec
+		program []int
+		input   []int
+		output  []int
+		// violation is the address of the instruction that violated the spec, -1 for none
+		violation int
+	}{
+		{"day 2 program", SpecDay02, []int{1, 9, 10, 3, 2, 3, 11, 0, 99, 30, 40, 50}, nil, nil, -1},
+		{"day 2 immediate mode", SpecDay02, []int{1, 0, 0, 0, 1101, 1, 1, 0, 99}, nil, nil, 4},
+		{"day 2 output", SpecDay02, []int{104, 1, 99}, nil, nil, 0},
+		{"day 5 program", SpecDay05, compare8, []int{8}, []int{1000}, -1},
+		{"day 5 relative base", SpecDay05, []int{104, 1, 109, 1, 99}, nil, []int{1}, 2},
+		{"day 5 relative mode", SpecDay05, []int{204, 0, 99}, nil, nil, 0},
+		{"day 5 memory beyond the program", SpecDay05, []int{1101, 1, 1, 100, 99}, nil, nil, 0},
+		{"day 9 program", SpecDay09, quine, nil, quine, -1},
+		{"day 9 memory beyond the program", SpecDay09, []int{1101, 1, 1, 100, 4, 100, 99}, nil, []int{2}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComputer(tt.program, WithSpec(tt.spec))
+			c.Send(tt.input...)
+			c.CloseInput()
+			result, err := c.Run()
+			if out := c.TakeOutput(); !reflect.DeepEqual(out, tt.output) {
+				t.Errorf("output %v, want %v", out, tt.output)
+			}
+			if tt.violation < 0 {
+				if err != nil {
+					t.Fatalf("error %v", err)
+				}
+				return
+			}
+			var specErr *SpecError
+			if !errors.As(err, &specErr) || !errors.Is(err, ErrSpecViolation) {
+				t.Fatalf("error %v, want a SpecError", err)
+			}
+			if result.Reason != HaltLimit || specErr.IP != tt.violation || specErr.Spec != tt.spec {
+				t.Errorf("stopped with %v at %d for %v, want a limit at %d for %v", result.Reason, specErr.IP,
+					specErr.Spec, tt.violation, tt.spec)
+			}
+		})
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	for _, spec := range []Spec{SpecDay02, SpecDay05, SpecDay09} {
+		if parsed, err := ParseSpec(spec.String()); err != nil || parsed != spec {
+			t.Errorf("parsed %v as %v %v", spec, parsed, err)
+		}
+	}
+	if spec, err := ParseSpec("day11"); err != nil || spec != SpecDay09 {
+		t.Errorf("parsed day11 as %v %v, want day09", spec, err)
+	}
+	if _, err := ParseSpec("day03"); err == nil {
+		t.Errorf("parsed day03")
+	}
+}
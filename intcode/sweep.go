@@ -0,0 +1,203 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"runtime"
+	"sync"
+)
+
+// ErrNoMatch is returned by FindPatch when no assignment hits the target
+var ErrNoMatch = errors.New("no patch assignment matches the target")
+
+// Patch overwrites the memory address with every value from Min to Max, both included
+type Patch struct {
+	Address  int
+	Min, Max int
+}
+
+// Assignment holds one value per patch, in the order of the patches
+type Assignment []int
+
+// Outcome is what a patched program left behind when it stopped
+type Outcome struct {
+	Memory  []int
+	Outputs []int
+}
+
+// Target decides whether an outcome is a match
+type Target func(o *Outcome) bool
+
+// MemoryEquals matches outcomes whose memory holds val at the address
+func MemoryEquals(address, val int) Target {
+	return func(o *Outcome) bool {
+		return address < len(o.Memory) && o.Memory[address] == val
+	}
+}
+
+// LastOutputEquals matches outcomes whose last output is val
+func LastOutputEquals(val int) Target {
+	return func(o *Outcome) bool {
+		return len(o.Outputs) > 0 && o.Outputs[len(o.Outputs)-1] == val
+	}
+}
+
+// Sweep runs a program once for every combination of patch values. The last patch varies fastest, like the
+// innermost of nested loops.
+type Sweep struct {
+	Program []int
+	Patches []Patch
+	// Inputs are sent to every run before its input is closed
+	Inputs []int
+	Target Target
+	// First stops the sweep at the first match in sweep order
+	First bool
+	// Workers is the number of programs run in parallel, GOMAXPROCS when zero
+	Workers int
+	// Limits sandboxes every run, runs that exceed a limit do not match. Without a context of its own every run
+	// stops when the sweep context is cancelled.
+	Limits Limits
+	// Logger gets the runs that do not match because they faulted or stopped early at debug level, nothing is
+	// logged when nil
+	Logger *zap.Logger
+}
+
+// Run returns the matching assignments in sweep order. Runs that fault, for example because a patch produced an
+// invalid instruction, or exceed a limit do not match. A cancelled context stops the sweep with the context error.
+func (s *Sweep) Run(ctx context.Context) ([]Assignment, error) {
+	total := 1
+	for _, p := range s.Patches {
+		if p.Max < p.Min {
+			return nil, fmt.Errorf("empty patch range %d..%d for address %d", p.Min, p.Max, p.Address)
+		}
+		total *= p.Max - p.Min + 1
+	}
+
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	limits := s.Limits
+	if limits.Context == nil {
+		limits.Context = ctx
+	}
+
+	jobs := make(chan int)
+	var mu sync.Mutex
+	matches := make(map[int]Assignment)
+	// firstMatch is the lowest matching index so far, later indices need not run when only the first match is wanted
+	firstMatch := total
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				mu.Lock()
+				skip := s.First && index > firstMatch
+				mu.Unlock()
+				if skip {
+					continue
+				}
+
+				values := s.assignment(index)
+				if !s.try(values, limits) {
+					continue
+				}
+
+				mu.Lock()
+				matches[index] = values
+				if index < firstMatch {
+					firstMatch = index
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+dispatch:
+	for index := 0; index < total; index++ {
+		mu.Lock()
+		done := s.First && index > firstMatch
+		mu.Unlock()
+		if done {
+			break
+		}
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Assignment
+	for index := 0; index < total && len(result) < len(matches); index++ {
+		if values, ok := matches[index]; ok {
+			result = append(result, values)
+			if s.First {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// assignment decodes the sweep index into patch values
+func (s *Sweep) assignment(index int) Assignment {
+	values := make(Assignment, len(s.Patches))
+	for i := len(s.Patches) - 1; i >= 0; i-- {
+		p := s.Patches[i]
+		size := p.Max - p.Min + 1
+		values[i] = p.Min + index%size
+		index /= size
+	}
+	return values
+}
+
+// try runs the program with the patch values and reports whether it hit the target
+func (s *Sweep) try(values Assignment, limits Limits) (match bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			orNop(s.Logger).Debug("patched program faulted", zap.Ints("values", values), zap.Any("fault", r))
+			match = false
+		}
+	}()
+
+	c := NewComputer(s.Program, WithLimits(limits))
+	for i, p := range s.Patches {
+		*c.cell(p.Address) = values[i]
+	}
+	c.Send(s.Inputs...)
+	c.CloseInput()
+	if result, err := c.Run(); err != nil {
+		orNop(s.Logger).Debug("patched program stopped", zap.Ints("values", values), zap.Stringer("reason", result.Reason), zap.Error(err))
+		return false
+	}
+	return s.Target(&Outcome{Memory: c.memory, Outputs: c.outQueue})
+}
+
+// FindPatch returns the first assignment, in sweep order, for which the program hits the target
+func FindPatch(program []int, target Target, patches ...Patch) (Assignment, error) {
+	s := Sweep{Program: program, Patches: patches, Target: target, First: true}
+	matches, err := s.Run(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, ErrNoMatch
+	}
+	return matches[0], nil
+}
@@ -0,0 +1,72 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSweep(t *testing.T) {
+	nounVerb := []Patch{{Address: 1, Min: 0, Max: 4}, {Address: 2, Min: 0, Max: 4}}
+	tests := []struct {
+		name    string
+		sweep   Sweep
+		want    []Assignment
+		wantErr error
+	}{
+		{"every match", Sweep{Program: []int{1, 0, 0, 0, 99}, Patches: nounVerb, Target: MemoryEquals(0, 2)},
+			[]Assignment{{0, 0}, {1, 0}, {1, 1}, {3, 2}}, nil},
+		{"every match on one worker", Sweep{Program: []int{1, 0, 0, 0, 99}, Patches: nounVerb,
+			Target: MemoryEquals(0, 2), Workers: 1},
+			[]Assignment{{0, 0}, {1, 0}, {1, 1}, {3, 2}}, nil},
+		{"first match", Sweep{Program: []int{2, 0, 0, 0, 99}, Patches: nounVerb, Target: MemoryEquals(0, 4),
+			First: true},
+			[]Assignment{{0, 0}}, nil},
+		{"output with input", Sweep{Program: []int{3, 9, 1, 9, 10, 9, 4, 9, 99, 0, 0},
+			Patches: []Patch{{Address: 10, Min: 0, Max: 9}}, Inputs: []int{5}, Target: LastOutputEquals(12)},
+			[]Assignment{{7}}, nil},
+		{"faults do not match", Sweep{Program: []int{104, 0, 99},
+			Patches: []Patch{{Address: 0, Min: 98, Max: 104}}, Target: LastOutputEquals(0)},
+			[]Assignment{{104}}, nil},
+		{"limits do not match", Sweep{Program: []int{1105, 1, 0, 104, 0, 99},
+			Patches: []Patch{{Address: 1, Min: 0, Max: 1}}, Target: LastOutputEquals(0), Limits: Limits{MaxSteps: 100}},
+			[]Assignment{{0}}, nil},
+		{"no match", Sweep{Program: []int{1, 0, 0, 0, 99}, Patches: nounVerb, Target: MemoryEquals(0, -1)},
+			nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := tt.sweep.Run(context.Background())
+			if err != tt.wantErr {
+				t.Fatalf("error %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(matches, tt.want) {
+				t.Errorf("matches %v, want %v", matches, tt.want)
+			}
+		})
+	}
+}
+
+func TestSweepErrors(t *testing.T) {
+	s := Sweep{Program: []int{99}, Patches: []Patch{{Address: 0, Min: 2, Max: 1}}, Target: MemoryEquals(0, 0)}
+	if _, err := s.Run(context.Background()); err == nil {
+		t.Errorf("swept an empty range")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s = Sweep{Program: []int{99}, Patches: []Patch{{Address: 0, Min: 0, Max: 1000}}, Target: MemoryEquals(0, 0)}
+	if _, err := s.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("error %v, want context.Canceled", err)
+	}
+
+	if _, err := FindPatch([]int{1, 0, 0, 0, 99}, MemoryEquals(0, -1), Patch{Address: 1, Max: 4}); err != ErrNoMatch {
+		t.Errorf("error %v, want ErrNoMatch", err)
+	}
+	values, err := FindPatch([]int{1, 0, 0, 0, 99}, MemoryEquals(0, 100), Patch{Address: 1, Max: 4},
+		Patch{Address: 2, Max: 4})
+	if err != nil || !reflect.DeepEqual(values, Assignment{0, 4}) {
+		t.Errorf("found %v %v, want [0 4]", values, err)
+	}
+}
@@ -0,0 +1,732 @@
+package intcode
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxExprTerms and maxExprDegree bound the expressions the symbolic engine tracks
+	maxExprTerms  = 64
+	maxExprDegree = 8
+	// maxSymbolicAddress bounds the memory of symbolic and concrete runs alike
+	maxSymbolicAddress = 1 << 20
+)
+
+// maxSymbolicSteps stops symbolic and concrete runs of programs that do not halt
+var maxSymbolicSteps = 10000000
+
+var (
+	// ErrSymbolicControl means the control flow or an address depends on a symbolic value
+	ErrSymbolicControl = errors.New("control flow depends on a symbolic value")
+	// ErrExprTooComplex means an expression grew beyond the tracked size
+	ErrExprTooComplex = errors.New("symbolic expression too complex")
+	// ErrNoSolution means no assignment of the variables hits the target
+	ErrNoSolution = errors.New("no assignment hits the target")
+	// ErrSymbolicAddress means the program accessed a negative address or one beyond the memory of a symbolic run.
+	// The address may only be out of range for some assignments, so the solvers enumerate them.
+	ErrSymbolicAddress = errors.New("address out of range in a symbolic run")
+)
+
+// Variable is a symbolic value with an inclusive domain
+type Variable struct {
+	Name     string
+	Min, Max int
+}
+
+// Expr is a polynomial with integer coefficients over the variables of a Symbolic run. Terms are keyed by the sorted
+// variable indices of their monomial, the constant term has the empty key. An opaque expression stands for a value
+// read through a symbolic address, it is only an error to depend on it.
+type Expr struct {
+	terms  map[string]int
+	names  []string
+	opaque bool
+}
+
+func constExpr(val int) *Expr {
+	e := &Expr{terms: make(map[string]int)}
+	if val != 0 {
+		e.terms[""] = val
+	}
+	return e
+}
+
+func varExpr(index int, names []string) *Expr {
+	return &Expr{terms: map[string]int{strconv.Itoa(index): 1}, names: names}
+}
+
+func opaqueExpr() *Expr {
+	return &Expr{opaque: true}
+}
+
+// Opaque reports whether the value was read through a symbolic address and has no known expression
+func (e *Expr) Opaque() bool {
+	return e.opaque
+}
+
+// monomial decodes a term key into its variable indices
+func monomial(key string) []int {
+	if key == "" {
+		return nil
+	}
+	var vars []int
+	for _, s := range strings.Split(key, ",") {
+		v, _ := strconv.Atoi(s)
+		vars = append(vars, v)
+	}
+	return vars
+}
+
+func monomialKey(vars []int) string {
+	sort.Ints(vars)
+	parts := make([]string, len(vars))
+	for i, v := range vars {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Const returns the value of a constant expression
+func (e *Expr) Const() (int, bool) {
+	if e.opaque {
+		return 0, false
+	}
+	for key := range e.terms {
+		if key != "" {
+			return 0, false
+		}
+	}
+	return e.terms[""], true
+}
+
+// Degree returns the highest total degree of any term
+func (e *Expr) Degree() int {
+	degree := 0
+	for key := range e.terms {
+		if d := len(monomial(key)); d > degree {
+			degree = d
+		}
+	}
+	return degree
+}
+
+// Eval evaluates the expression with one value per variable
+func (e *Expr) Eval(values []int) int {
+	sum := 0
+	for key, coef := range e.terms {
+		prod := coef
+		for _, v := range monomial(key) {
+			prod *= values[v]
+		}
+		sum += prod
+	}
+	return sum
+}
+
+func (e *Expr) String() string {
+	if e.opaque {
+		return "?"
+	}
+	if len(e.terms) == 0 {
+		return "0"
+	}
+	keys := make([]string, 0, len(e.terms))
+	for key := range e.terms {
+		keys = append(keys, key)
+	}
+	// highest degree first, the constant last
+	sort.Slice(keys, func(i, j int) bool {
+		di, dj := len(monomial(keys[i])), len(monomial(keys[j]))
+		if di != dj {
+			return di > dj
+		}
+		return keys[i] < keys[j]
+	})
+
+	var b strings.Builder
+	for i, key := range keys {
+		coef := e.terms[key]
+		if i > 0 {
+			if coef < 0 {
+				b.WriteString(" - ")
+				coef = -coef
+			} else {
+				b.WriteString(" + ")
+			}
+		}
+		var factors []string
+		if coef != 1 || key == "" {
+			factors = append(factors, strconv.Itoa(coef))
+		}
+		for _, v := range monomial(key) {
+			factors = append(factors, e.name(v))
+		}
+		b.WriteString(strings.Join(factors, "*"))
+	}
+	return b.String()
+}
+
+func (e *Expr) name(v int) string {
+	if v < len(e.names) {
+		return e.names[v]
+	}
+	return fmt.Sprintf("v%d", v)
+}
+
+func addExpr(a, b *Expr) (*Expr, error) {
+	if a.opaque || b.opaque {
+		return opaqueExpr(), nil
+	}
+	sum := &Expr{terms: make(map[string]int, len(a.terms)+len(b.terms)), names: a.names}
+	if sum.names == nil {
+		sum.names = b.names
+	}
+	for key, coef := range a.terms {
+		sum.terms[key] = coef
+	}
+	for key, coef := range b.terms {
+		if total := sum.terms[key] + coef; total != 0 {
+			sum.terms[key] = total
+		} else {
+			delete(sum.terms, key)
+		}
+	}
+	if len(sum.terms) > maxExprTerms {
+		return nil, ErrExprTooComplex
+	}
+	return sum, nil
+}
+
+func mulExpr(a, b *Expr) (*Expr, error) {
+	if a.opaque || b.opaque {
+		return opaqueExpr(), nil
+	}
+	prod := &Expr{terms: make(map[string]int), names: a.names}
+	if prod.names == nil {
+		prod.names = b.names
+	}
+	for ka, ca := range a.terms {
+		for kb, cb := range b.terms {
+			vars := append(monomial(ka), monomial(kb)...)
+			if len(vars) > maxExprDegree {
+				return nil, ErrExprTooComplex
+			}
+			key := monomialKey(vars)
+			if total := prod.terms[key] + ca*cb; total != 0 {
+				prod.terms[key] = total
+			} else {
+				delete(prod.terms, key)
+			}
+		}
+	}
+	if len(prod.terms) > maxExprTerms {
+		return nil, ErrExprTooComplex
+	}
+	return prod, nil
+}
+
+// coefficients splits the expression into a*v + b for a variable the expression is linear in
+func (e *Expr) coefficients(v int) (a, b *Expr, ok bool) {
+	if e.opaque {
+		return nil, nil, false
+	}
+	a = &Expr{terms: make(map[string]int), names: e.names}
+	b = &Expr{terms: make(map[string]int), names: e.names}
+	for key, coef := range e.terms {
+		vars := monomial(key)
+		count := 0
+		var rest []int
+		for _, x := range vars {
+			if x == v {
+				count++
+			} else {
+				rest = append(rest, x)
+			}
+		}
+		switch count {
+		case 0:
+			b.terms[key] = coef
+		case 1:
+			a.terms[monomialKey(rest)] += coef
+		default:
+			return nil, nil, false
+		}
+	}
+	return a, b, len(a.terms) > 0
+}
+
+// Symbolic executes a program where some memory cells and inputs are variables. Arithmetic on variables builds
+// polynomial expressions, so the final memory and the outputs are known as functions of the variables.
+type Symbolic struct {
+	program []int
+	vars    []Variable
+	names   []string
+	// cells maps memory addresses to variables
+	cells map[int]int
+	// inputs are either constants or variables
+	inputs []*Expr
+	log    *zap.Logger
+}
+
+// NewSymbolic prepares a symbolic run of the program
+func NewSymbolic(program []int) *Symbolic {
+	return &Symbolic{program: program, cells: make(map[int]int), log: zap.NewNop()}
+}
+
+// SetLogger logs at debug level how the solvers find a solution
+func (s *Symbolic) SetLogger(logger *zap.Logger) {
+	s.log = orNop(logger)
+}
+
+func (s *Symbolic) addVar(name string, min, max int) int {
+	s.vars = append(s.vars, Variable{Name: name, Min: min, Max: max})
+	s.names = append(s.names, name)
+	return len(s.vars) - 1
+}
+
+// MemoryVar makes the memory cell at address a variable with the domain min to max
+func (s *Symbolic) MemoryVar(address int, name string, min, max int) {
+	s.cells[address] = s.addVar(name, min, max)
+}
+
+// InputVar queues a variable input with the domain min to max
+func (s *Symbolic) InputVar(name string, min, max int) {
+	s.inputs = append(s.inputs, varExpr(s.addVar(name, min, max), s.names))
+}
+
+// Input queues constant input values
+func (s *Symbolic) Input(values ...int) {
+	for _, val := range values {
+		s.inputs = append(s.inputs, constExpr(val))
+	}
+}
+
+// Vars returns the variables in the order they were declared
+func (s *Symbolic) Vars() []Variable {
+	return s.vars
+}
+
+// SymbolicResult holds the final memory and the outputs of a symbolic run as expressions
+type SymbolicResult struct {
+	mem     *symbolicMemory
+	Outputs []*Expr
+}
+
+// Memory returns the expression held by the memory cell. Cells beyond memory are zero.
+func (r *SymbolicResult) Memory(address int) *Expr {
+	return r.mem.load(address)
+}
+
+// symbolicMemory stores concrete values with the symbolic cells on the side. The concrete memory starts as large as
+// the program and grows on demand.
+type symbolicMemory struct {
+	concrete []int
+	symbolic map[int]*Expr
+	names    []string
+}
+
+// checkAddress fails with ErrSymbolicAddress for addresses a run may not access
+func checkAddress(address int) error {
+	if address < 0 || address > maxSymbolicAddress {
+		return fmt.Errorf("%w: %d", ErrSymbolicAddress, address)
+	}
+	return nil
+}
+
+func (m *symbolicMemory) concreteValue(address int) int {
+	if address < 0 || address >= len(m.concrete) {
+		return 0
+	}
+	return m.concrete[address]
+}
+
+func (m *symbolicMemory) load(address int) *Expr {
+	if e, ok := m.symbolic[address]; ok {
+		return e
+	}
+	e := constExpr(m.concreteValue(address))
+	e.names = m.names
+	return e
+}
+
+func (m *symbolicMemory) concreteAt(address int) (int, error) {
+	if e, ok := m.symbolic[address]; ok {
+		if val, ok := e.Const(); ok {
+			return val, nil
+		}
+		return 0, ErrSymbolicControl
+	}
+	if err := checkAddress(address); err != nil {
+		return 0, err
+	}
+	return m.concreteValue(address), nil
+}
+
+// store writes the expression to an address that passed checkAddress, growing memory as needed
+func (m *symbolicMemory) store(address int, e *Expr) {
+	val, ok := e.Const()
+	if !ok {
+		m.symbolic[address] = e
+		return
+	}
+	delete(m.symbolic, address)
+	if address >= len(m.concrete) {
+		if val == 0 {
+			return
+		}
+		size := 2 * len(m.concrete)
+		if size <= address {
+			size = address + 1
+		}
+		grown := make([]int, size)
+		copy(grown, m.concrete)
+		m.concrete = grown
+	}
+	m.concrete[address] = val
+}
+
+// Execute runs the program symbolically. It fails with ErrSymbolicControl when an instruction, a written address,
+// a jump or a comparison depends on a variable, with ErrExprTooComplex when an expression grows too large and with
+// ErrSymbolicAddress when it accesses a negative address or one beyond a million cells.
+func (s *Symbolic) Execute() (result *SymbolicResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("symbolic run faulted: %v", r)
+		}
+	}()
+
+	mem := &symbolicMemory{concrete: append([]int(nil), s.program...), symbolic: make(map[int]*Expr), names: s.names}
+	for address, v := range s.cells {
+		if err := checkAddress(address); err != nil {
+			return nil, err
+		}
+		mem.symbolic[address] = varExpr(v, s.names)
+	}
+
+	result = &SymbolicResult{mem: mem}
+	// variables may have been declared after an input was queued, so the inputs get the final names
+	inputs := make([]*Expr, len(s.inputs))
+	for i, e := range s.inputs {
+		inputs[i] = &Expr{terms: e.terms, names: s.names}
+	}
+	ip, relativeBase := 0, 0
+
+	// address resolves the memory address of the parameter
+	address := func(pos int) (int, error) {
+		instruction, _ := mem.concreteAt(ip)
+		mode := instruction / 100
+		for i := 0; i < pos; i++ {
+			mode /= 10
+		}
+		param, err := mem.concreteAt(ip + 1 + pos)
+		if err != nil && mode%10 != IMMEDIATE_MODE {
+			return 0, err
+		}
+		switch mode % 10 {
+		case IMMEDIATE_MODE:
+			return ip + 1 + pos, nil
+		case POSITION_MODE:
+			return param, checkAddress(param)
+		case RELATIVE_MODE:
+			return relativeBase + param, checkAddress(relativeBase + param)
+		default:
+			return 0, fmt.Errorf("unknown addressing mode at address %d", ip)
+		}
+	}
+	// arg reads the parameter, a read through a symbolic address gives an opaque value
+	arg := func(pos int) (*Expr, error) {
+		a, err := address(pos)
+		if errors.Is(err, ErrSymbolicControl) {
+			return opaqueExpr(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return mem.load(a), nil
+	}
+	concreteArg := func(pos int) (int, error) {
+		a, err := address(pos)
+		if err != nil {
+			return 0, err
+		}
+		return mem.concreteAt(a)
+	}
+
+	for step := 0; ; step++ {
+		if step == maxSymbolicSteps {
+			return nil, fmt.Errorf("program did not halt within %d steps", maxSymbolicSteps)
+		}
+		instruction, err := mem.concreteAt(ip)
+		if err != nil {
+			return nil, err
+		}
+
+		switch instruction % 100 {
+		case ADD, MUL:
+			a, err := arg(0)
+			if err != nil {
+				return nil, err
+			}
+			b, err := arg(1)
+			if err != nil {
+				return nil, err
+			}
+			out, err := address(2)
+			if err != nil {
+				return nil, err
+			}
+			var res *Expr
+			if instruction%100 == ADD {
+				res, err = addExpr(a, b)
+			} else {
+				res, err = mulExpr(a, b)
+			}
+			if err != nil {
+				return nil, err
+			}
+			mem.store(out, res)
+			ip += 4
+		case INPUT:
+			if len(inputs) == 0 {
+				return nil, fmt.Errorf("program wants more input at address %d", ip)
+			}
+			out, err := address(0)
+			if err != nil {
+				return nil, err
+			}
+			mem.store(out, inputs[0])
+			inputs = inputs[1:]
+			ip += 2
+		case OUTPUT:
+			e, err := arg(0)
+			if err != nil {
+				return nil, err
+			}
+			result.Outputs = append(result.Outputs, e)
+			ip += 2
+		case JMP_IF_TRUE, JMP_IF_FALSE:
+			cond, err := concreteArg(0)
+			if err != nil {
+				return nil, fmt.Errorf("jump at address %d: %w", ip, err)
+			}
+			if (cond != 0) == (instruction%100 == JMP_IF_TRUE) {
+				if ip, err = concreteArg(1); err != nil {
+					return nil, fmt.Errorf("jump target: %w", err)
+				}
+			} else {
+				ip += 3
+			}
+		case LESS_THAN, EQUALS:
+			a, err := concreteArg(0)
+			if err != nil {
+				return nil, fmt.Errorf("comparison at address %d: %w", ip, err)
+			}
+			b, err := concreteArg(1)
+			if err != nil {
+				return nil, fmt.Errorf("comparison at address %d: %w", ip, err)
+			}
+			out, err := address(2)
+			if err != nil {
+				return nil, err
+			}
+			res := 0
+			if (instruction%100 == LESS_THAN && a < b) || (instruction%100 == EQUALS && a == b) {
+				res = 1
+			}
+			mem.store(out, constExpr(res))
+			ip += 4
+		case ADJ_RELATIVE_BASE:
+			adj, err := concreteArg(0)
+			if err != nil {
+				return nil, fmt.Errorf("relative base at address %d: %w", ip, err)
+			}
+			relativeBase += adj
+			ip += 2
+		case HALT:
+			return result, nil
+		default:
+			return nil, fmt.Errorf("instruction %d does not exist at address %d", instruction, ip)
+		}
+	}
+}
+
+// Solution assigns a value to every variable, in declaration order
+type Solution []int
+
+// SolveMemory finds variable values for which the memory cell ends up holding target
+func (s *Symbolic) SolveMemory(address, target int) (Solution, error) {
+	return s.solve(target, func(r *SymbolicResult) (*Expr, error) {
+		return r.Memory(address), nil
+	}, func(c *Computer) (int, bool) {
+		return c.Int(address)
+	})
+}
+
+// SolveOutput finds variable values for which the output with the index ends up being target
+func (s *Symbolic) SolveOutput(index, target int) (Solution, error) {
+	return s.solve(target, func(r *SymbolicResult) (*Expr, error) {
+		if index >= len(r.Outputs) {
+			return nil, fmt.Errorf("program has %d outputs", len(r.Outputs))
+		}
+		return r.Outputs[index], nil
+	}, func(c *Computer) (int, bool) {
+		if index >= len(c.outQueue) {
+			return 0, false
+		}
+		return c.outQueue[index], true
+	})
+}
+
+// solve uses the symbolic expression when execution did not branch on a variable and enumerates the domain with
+// concrete runs otherwise
+func (s *Symbolic) solve(target int, expr func(r *SymbolicResult) (*Expr, error), concrete func(c *Computer) (int, bool)) (Solution, error) {
+	result, err := s.Execute()
+	if err == nil {
+		var e *Expr
+		if e, err = expr(result); err != nil {
+			return nil, err
+		}
+		if !e.Opaque() {
+			s.log.Debug("solving symbolically", zap.Stringer("expression", e), zap.Int("target", target))
+			return s.solveExpr(e, target)
+		}
+		err = ErrSymbolicControl
+	}
+	if !errors.Is(err, ErrSymbolicControl) && !errors.Is(err, ErrExprTooComplex) && !errors.Is(err, ErrSymbolicAddress) {
+		return nil, err
+	}
+
+	s.log.Debug("falling back to enumeration", zap.Error(err))
+	var solution Solution
+	err = s.enumerateExcept(-1, make([]int, len(s.vars)), func(values []int) bool {
+		if val, ok := s.runConcrete(values, concrete); ok && val == target {
+			solution = append(Solution(nil), values...)
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if solution == nil {
+		return nil, ErrNoSolution
+	}
+	return solution, nil
+}
+
+// solveExpr picks a variable the expression is linear in and solves for it while enumerating the others. Without
+// such a variable the expression is evaluated over the whole domain, which is still far cheaper than running.
+func (s *Symbolic) solveExpr(e *Expr, target int) (Solution, error) {
+	values := make([]int, len(s.vars))
+	for v := len(s.vars) - 1; v >= 0; v-- {
+		a, b, ok := e.coefficients(v)
+		if !ok {
+			continue
+		}
+
+		// enumerate every variable but v, which is moved to the end of the order
+		domain := s.vars[v]
+		var solution Solution
+		err := s.enumerateExcept(v, values, func(values []int) bool {
+			coef, rest := a.Eval(values), b.Eval(values)
+			if coef == 0 {
+				if rest == target {
+					values[v] = domain.Min
+					solution = append(Solution(nil), values...)
+					return true
+				}
+				return false
+			}
+			if (target-rest)%coef != 0 {
+				return false
+			}
+			x := (target - rest) / coef
+			if x < domain.Min || x > domain.Max {
+				return false
+			}
+			values[v] = x
+			solution = append(Solution(nil), values...)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		if solution == nil {
+			return nil, ErrNoSolution
+		}
+		return solution, nil
+	}
+
+	var solution Solution
+	err := s.enumerateExcept(-1, values, func(values []int) bool {
+		if e.Eval(values) == target {
+			solution = append(Solution(nil), values...)
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if solution == nil {
+		return nil, ErrNoSolution
+	}
+	return solution, nil
+}
+
+// enumerateExcept calls visit for every assignment of all variables but skip until visit returns true. The first
+// variable varies slowest, skip may be -1 to enumerate them all.
+func (s *Symbolic) enumerateExcept(skip int, values []int, visit func(values []int) bool) error {
+	var order []int
+	for v := range s.vars {
+		if v == skip {
+			continue
+		}
+		if s.vars[v].Max < s.vars[v].Min {
+			return fmt.Errorf("empty domain for %s", s.vars[v].Name)
+		}
+		order = append(order, v)
+		values[v] = s.vars[v].Min
+	}
+
+	for {
+		if visit(values) {
+			return nil
+		}
+		i := len(order) - 1
+		for ; i >= 0; i-- {
+			v := order[i]
+			if values[v] < s.vars[v].Max {
+				values[v]++
+				break
+			}
+			values[v] = s.vars[v].Min
+		}
+		if i < 0 {
+			return nil
+		}
+	}
+}
+
+// runConcrete runs the program with the variables replaced by the values, within the same bounds as a symbolic run
+func (s *Symbolic) runConcrete(values []int, concrete func(c *Computer) (int, bool)) (int, bool) {
+	c := NewComputer(s.program, WithLimits(Limits{MaxSteps: maxSymbolicSteps, MaxAddress: maxSymbolicAddress}))
+	for address, v := range s.cells {
+		if checkAddress(address) != nil {
+			return 0, false
+		}
+		*c.cell(address) = values[v]
+	}
+	for _, e := range s.inputs {
+		c.Send(e.Eval(values))
+	}
+	c.CloseInput()
+	if _, err := c.Run(); err != nil {
+		return 0, false
+	}
+	return concrete(c)
+}
@@ -0,0 +1,173 @@
+package intcode
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSymbolicExpressions(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		setup   func(s *Symbolic)
+		memory  map[int]string
+		outputs []string
+	}{
+		{"sum", []int{1, 5, 6, 0, 99, 0, 0}, func(s *Symbolic) {
+			s.MemoryVar(5, "x", 0, 9)
+			s.MemoryVar(6, "y", 0, 9)
+		}, map[int]string{0: "x + y", 1: "5"}, nil},
+		{"product plus variable", []int{2, 9, 10, 0, 1, 0, 9, 0, 99, 0, 0}, func(s *Symbolic) {
+			s.MemoryVar(9, "x", 0, 9)
+			s.MemoryVar(10, "y", 0, 9)
+		}, map[int]string{0: "x*y + x"}, nil},
+		{"input", []int{3, 9, 1002, 9, -3, 9, 4, 9, 99, 0}, func(s *Symbolic) {
+			s.InputVar("a", 0, 10)
+		}, map[int]string{9: "-3*a"}, []string{"-3*a"}},
+		{"constant input", []int{3, 11, 3, 12, 1, 11, 12, 13, 4, 13, 99, 0, 0, 0}, func(s *Symbolic) {
+			s.Input(4)
+			s.InputVar("b", 0, 10)
+		}, nil, []string{"b + 4"}},
+	}
+	// long adds two cells beyond 3000 into memory 0
+	long := make([]int, 3010)
+	copy(long, []int{1, 3005, 3006, 0, 99})
+	long[3005], long[3006] = 7, 8
+	tests = append(tests, struct {
+		name    string
+		program []int
+		setup   func(s *Symbolic)
+		memory  map[int]string
+		outputs []string
+	}{"long program", long, func(s *Symbolic) {
+		s.MemoryVar(3006, "x", 0, 9)
+	}, map[int]string{0: "x + 7", 3005: "7", 5000: "0"}, nil})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSymbolic(tt.program)
+			tt.setup(s)
+			result, err := s.Execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for address, want := range tt.memory {
+				if got := result.Memory(address).String(); got != want {
+					t.Errorf("memory %d is %s, want %s", address, got, want)
+				}
+			}
+			var outputs []string
+			for _, e := range result.Outputs {
+				outputs = append(outputs, e.String())
+			}
+			if !reflect.DeepEqual(outputs, tt.outputs) {
+				t.Errorf("outputs %v, want %v", outputs, tt.outputs)
+			}
+		})
+	}
+}
+
+func TestSymbolicSolve(t *testing.T) {
+	// the runs that loop forever stop sooner
+	defer func(steps int) {
+		maxSymbolicSteps = steps
+	}(maxSymbolicSteps)
+	maxSymbolicSteps = 100000
+
+	// memory 0 ends up as 100*x + y
+	linear := []int{1002, 9, 100, 0, 1, 0, 10, 0, 99, 0, 0}
+	// outputs 1 when the input is 5, which compares a variable
+	equals5 := []int{3, 9, 1008, 9, 5, 10, 4, 10, 99, 0, 0}
+	tests := []struct {
+		name    string
+		program []int
+		setup   func(s *Symbolic)
+		solve   func(s *Symbolic) (Solution, error)
+		want    Solution
+		wantErr error
+	}{
+		{"linear", linear, func(s *Symbolic) {
+			s.MemoryVar(9, "x", 0, 99)
+			s.MemoryVar(10, "y", 0, 99)
+		}, func(s *Symbolic) (Solution, error) {
+			return s.SolveMemory(0, 1234)
+		}, Solution{12, 34}, nil},
+		{"linear out of the domain", linear, func(s *Symbolic) {
+			s.MemoryVar(9, "x", 0, 9)
+			s.MemoryVar(10, "y", 0, 99)
+		}, func(s *Symbolic) (Solution, error) {
+			return s.SolveMemory(0, 1234)
+		}, nil, ErrNoSolution},
+		{"addresses from variables", []int{1, 0, 0, 0, 99}, func(s *Symbolic) {
+			s.MemoryVar(1, "noun", 0, 4)
+			s.MemoryVar(2, "verb", 0, 4)
+		}, func(s *Symbolic) (Solution, error) {
+			return s.SolveMemory(0, 100)
+		}, Solution{0, 4}, nil},
+		{"branch on a variable", equals5, func(s *Symbolic) {
+			s.InputVar("a", 0, 10)
+		}, func(s *Symbolic) (Solution, error) {
+			return s.SolveOutput(0, 1)
+		}, Solution{5}, nil},
+		{"branch without a solution", equals5, func(s *Symbolic) {
+			s.InputVar("a", 0, 4)
+		}, func(s *Symbolic) (Solution, error) {
+			return s.SolveOutput(0, 1)
+		}, nil, ErrNoSolution},
+		{"loop for other values", []int{3, 12, 1008, 12, 5, 13, 1006, 13, 6, 104, 1, 99, 0, 0}, func(s *Symbolic) {
+			s.InputVar("a", 4, 5)
+		}, func(s *Symbolic) (Solution, error) {
+			return s.SolveOutput(0, 1)
+		}, Solution{5}, nil},
+		{"address out of range", []int{1101, 1, 1, 2000000, 99}, func(s *Symbolic) {
+			s.InputVar("a", 0, 1)
+		}, func(s *Symbolic) (Solution, error) {
+			return s.SolveMemory(2000000, 2)
+		}, nil, ErrNoSolution},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSymbolic(tt.program)
+			tt.setup(s)
+			solution, err := tt.solve(s)
+			if err != tt.wantErr {
+				t.Fatalf("error %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(solution, tt.want) {
+				t.Errorf("solution %v, want %v", solution, tt.want)
+			}
+		})
+	}
+}
+
+func TestSymbolicControl(t *testing.T) {
+	s := NewSymbolic([]int{3, 9, 1008, 9, 5, 10, 4, 10, 99, 0, 0})
+	s.InputVar("a", 0, 10)
+	if _, err := s.Execute(); !errors.Is(err, ErrSymbolicControl) {
+		t.Errorf("error %v, want ErrSymbolicControl", err)
+	}
+}
+
+func TestSymbolicAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		setup   func(s *Symbolic)
+	}{
+		{"negative write", []int{1101, 1, 1, -1, 99}, func(s *Symbolic) {}},
+		{"read beyond memory", []int{1, 2000000, 0, 0, 99}, func(s *Symbolic) {}},
+		{"relative read", []int{109, -5, 2201, 0, 0, 0, 99}, func(s *Symbolic) {}},
+		{"variable cell", []int{99}, func(s *Symbolic) {
+			s.MemoryVar(-2, "x", 0, 1)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSymbolic(tt.program)
+			tt.setup(s)
+			if _, err := s.Execute(); !errors.Is(err, ErrSymbolicAddress) {
+				t.Errorf("error %v, want ErrSymbolicAddress", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package intcode
+
+import (
+	"fmt"
+	"sort"
+)
+
+// labelSet is a sorted set of input indexes. Sets are never modified once built, so cells may share them.
+type labelSet []int
+
+// union returns the labels of both sets, reusing one of them when the other adds nothing
+func (s labelSet) union(o labelSet) labelSet {
+	switch {
+	case len(o) == 0:
+		return s
+	case len(s) == 0:
+		return o
+	}
+	out := make(labelSet, 0, len(s)+len(o))
+	i, j := 0, 0
+	for i < len(s) || j < len(o) {
+		switch {
+		case j == len(o) || i < len(s) && s[i] < o[j]:
+			out = append(out, s[i])
+			i++
+		case i == len(s) || o[j] < s[i]:
+			out = append(out, o[j])
+			j++
+		default:
+			out = append(out, s[i])
+			i++
+			j++
+		}
+	}
+	if len(out) == len(s) {
+		return s
+	}
+	if len(out) == len(o) {
+		return o
+	}
+	return out
+}
+
+// TaintedOutput is an output value with the inputs it depends on
+type TaintedOutput struct {
+	// Index counts the outputs before this one
+	Index int
+	Value int
+	// Data are the labels of the inputs the value was computed from, through arithmetic, comparisons and memory
+	Data []string
+	// Control are the labels of the inputs that decided a conditional jump executed before the output
+	Control []string
+	// Jumps are the addresses of those jumps
+	Jumps []int
+}
+
+func (o TaintedOutput) String() string {
+	return fmt.Sprintf("output %d = %d data %v control %v", o.Index, o.Value, o.Data, o.Control)
+}
+
+// Taint follows the input values through a run. Every input gets a label, an instruction writes the labels of
+// everything it read, so labels flow through arithmetic, comparisons and memory. The cells of an instruction count as
+// read too, so input that ends up in self-modified code taints what the code computes, as does a relative base that
+// was adjusted by input.
+//
+// Control dependence is tracked conservatively: once a conditional jump decided on input, by its condition or its
+// target, every later output depends on that input. Add Taint to a computer with WithTracer.
+type Taint struct {
+	labels  []string
+	memory  map[int]labelSet
+	base    labelSet
+	control labelSet
+	jumps   []int
+	inputs  int
+	outputs []TaintedOutput
+}
+
+// NewTaint creates a taint tracker that names the inputs by the labels, in the order the program reads them. Inputs
+// beyond the labels are named in0, in1 and so on by their index.
+func NewTaint(labels ...string) *Taint {
+	return &Taint{labels: labels, memory: make(map[int]labelSet)}
+}
+
+// label names the input with the index
+func (t *Taint) label(input int) string {
+	if input < len(t.labels) {
+		return t.labels[input]
+	}
+	return fmt.Sprintf("in%d", input)
+}
+
+func (t *Taint) names(s labelSet) []string {
+	names := make([]string, len(s))
+	for i, input := range s {
+		names[i] = t.label(input)
+	}
+	return names
+}
+
+// Trace moves the labels of an executed instruction
+func (t *Taint) Trace(c Inspector, e Event) {
+	// the labels of every parameter, including the cells of the instruction and the relative base it was addressed by
+	var params [3]labelSet
+	reads := e.Reads
+	write := writesParam(e.Opcode)
+	mode := e.Instruction / 100
+	for pos := 0; pos < paramCounts[e.Opcode]; pos++ {
+		m := mode % 10
+		mode /= 10
+		params[pos] = t.memory[e.IP].union(t.memory[e.IP+1+pos])
+		if pos == write || m == IMMEDIATE_MODE {
+			continue
+		}
+		if pos == 1 && (e.Opcode == JMP_IF_TRUE || e.Opcode == JMP_IF_FALSE) && !e.Jumped {
+			continue
+		}
+		if m == RELATIVE_MODE {
+			params[pos] = params[pos].union(t.base)
+		}
+		params[pos] = params[pos].union(t.memory[reads[0]])
+		reads = reads[1:]
+	}
+
+	switch e.Opcode {
+	case ADD, MUL, LESS_THAN, EQUALS:
+		t.set(e.Writes[0], params[0].union(params[1]))
+	case INPUT:
+		t.set(e.Writes[0], labelSet{t.inputs})
+		t.inputs++
+	case OUTPUT:
+		value, _ := c.Int(e.IP + 1)
+		if len(e.Reads) > 0 {
+			value, _ = c.Int(e.Reads[0])
+		}
+		t.outputs = append(t.outputs, TaintedOutput{
+			Index:   len(t.outputs),
+			Value:   value,
+			Data:    t.names(params[0]),
+			Control: t.names(t.control),
+			Jumps:   append([]int(nil), t.jumps...),
+		})
+	case JMP_IF_TRUE, JMP_IF_FALSE:
+		decided := params[0]
+		if e.Jumped {
+			decided = decided.union(params[1])
+		}
+		if len(decided) > 0 {
+			t.control = t.control.union(decided)
+			t.addJump(e.IP)
+		}
+	case ADJ_RELATIVE_BASE:
+		t.base = t.base.union(params[0])
+	}
+}
+
+// set replaces the labels of a memory cell
+func (t *Taint) set(address int, s labelSet) {
+	if len(s) == 0 {
+		delete(t.memory, address)
+		return
+	}
+	t.memory[address] = s
+}
+
+func (t *Taint) addJump(address int) {
+	i := sort.SearchInts(t.jumps, address)
+	if i < len(t.jumps) && t.jumps[i] == address {
+		return
+	}
+	t.jumps = append(t.jumps, 0)
+	copy(t.jumps[i+1:], t.jumps[i:])
+	t.jumps[i] = address
+}
+
+// Outputs returns the outputs so far with the inputs they depend on
+func (t *Taint) Outputs() []TaintedOutput {
+	return t.outputs
+}
+
+// Labels returns the labels of the inputs the memory cell was computed from
+func (t *Taint) Labels(address int) []string {
+	return t.names(t.memory[address])
+}
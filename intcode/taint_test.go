@@ -0,0 +1,91 @@
+package intcode
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTaint(t *testing.T) {
+	diagnostic, err := LoadProgram("testdata/diagnostic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// zeros are the nine passing tests of the first part of day 5, which do not depend on the input
+	var zeros []TaintedOutput
+	for i := 0; i < 9; i++ {
+		zeros = append(zeros, TaintedOutput{Index: i})
+	}
+
+	tests := []struct {
+		name    string
+		program []int
+		input   []int
+		want    []TaintedOutput
+	}{
+		{"equal to 8 by position", []int{3, 9, 8, 9, 10, 9, 4, 9, 99, -1, 8}, []int{8},
+			[]TaintedOutput{{Value: 1, Data: []string{"x"}}}},
+		{"less than 8 in place", []int{3, 3, 1107, -1, 8, 3, 4, 3, 99}, []int{5},
+			[]TaintedOutput{{Value: 1, Data: []string{"x"}}}},
+		{"jump by position", []int{3, 12, 6, 12, 15, 1, 13, 14, 13, 4, 13, 99, -1, 0, 1, 9}, []int{0},
+			[]TaintedOutput{{Value: 0, Control: []string{"x"}, Jumps: []int{2}}}},
+		{"jump in place", []int{3, 3, 1105, -1, 9, 1101, 0, 0, 12, 4, 12, 99, 1}, []int{3},
+			[]TaintedOutput{{Value: 1, Control: []string{"x"}, Jumps: []int{2}}}},
+		{"compare below 8", compare8, []int{7},
+			[]TaintedOutput{{Value: 999, Control: []string{"x"}, Jumps: []int{6, 13}}}},
+		{"compare equal to 8", compare8, []int{8},
+			[]TaintedOutput{{Value: 1000, Data: []string{"x"}, Control: []string{"x"}, Jumps: []int{6}}}},
+		{"compare above 8", compare8, []int{9},
+			[]TaintedOutput{{Value: 1001, Control: []string{"x"}, Jumps: []int{6, 13}}}},
+		{"diagnostic air conditioner", diagnostic, []int{1},
+			append(zeros, TaintedOutput{Index: 9, Value: 2845163})},
+		{"diagnostic thermal radiator", diagnostic, []int{5},
+			[]TaintedOutput{{Value: 9436229, Control: []string{"x"}, Jumps: []int{6}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taint := NewTaint("x")
+			c := NewComputer(tt.program, WithTracer(taint))
+			c.Send(tt.input...)
+			c.CloseInput()
+			if _, err := c.Run(); err != nil {
+				t.Fatal(err)
+			}
+			// printing treats empty and missing labels alike
+			if got, want := fmt.Sprint(taint.Outputs()), fmt.Sprint(tt.want); got != want {
+				t.Errorf("outputs %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTaintLabels(t *testing.T) {
+	// adds two inputs into the cell 13 and outputs it
+	program := []int{3, 11, 3, 12, 1, 11, 12, 13, 4, 13, 99, 0, 0, 0}
+	tests := []struct {
+		labels []string
+		// cells are the labels of some memory cells after the run
+		cells map[int][]string
+	}{
+		{[]string{"a", "b"}, map[int][]string{11: {"a"}, 12: {"b"}, 13: {"a", "b"}, 0: {}}},
+		{[]string{"a"}, map[int][]string{12: {"in1"}, 13: {"a", "in1"}}},
+		{nil, map[int][]string{11: {"in0"}, 13: {"in0", "in1"}}},
+	}
+	for _, tt := range tests {
+		taint := NewTaint(tt.labels...)
+		c := NewComputer(program, WithTracer(taint))
+		c.Send(3, 4)
+		c.CloseInput()
+		if _, err := c.Run(); err != nil {
+			t.Fatal(err)
+		}
+		for address, want := range tt.cells {
+			if got := taint.Labels(address); !reflect.DeepEqual(got, want) {
+				t.Errorf("labels %v: cell %d has %v, want %v", tt.labels, address, got, want)
+			}
+		}
+		if out := taint.Outputs(); len(out) != 1 || out[0].Value != 7 || !reflect.DeepEqual(out[0].Data, tt.cells[13]) {
+			t.Errorf("labels %v: outputs %v", tt.labels, out)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package intcode
+
+// Event describes an executed instruction
+type Event struct {
+	// Step counts the instructions executed before this one
+	Step int
+	// IP is the address of the instruction
+	IP int
+	// Instruction is the instruction value with its addressing modes, Opcode only the opcode
+	Instruction int
+	Opcode      int
+	// RelativeBase is the relative base the instruction ran with
+	RelativeBase int
+	// NextIP is the instruction pointer after the instruction, the IP again for HALT
+	NextIP int
+	// Jumped is set when a conditional jump was taken
+	Jumped bool
+	// Reads and Writes are the memory addresses the instruction accessed through position and relative parameters,
+	// immediate parameters are part of the instruction. They are only valid during the call of the tracer.
+	Reads  []int
+	Writes []int
+}
+
+// Tracer observes the instructions a computer executes. An INPUT instruction that suspends or finds the input closed
+// is not executed. The same tracer works for machines of every word type.
+type Tracer interface {
+	Trace(c Inspector, e Event)
+}
+
+// Inspector is the view of a machine a Tracer gets, independent of its word type
+type Inspector interface {
+	IP() int
+	RelativeBase() int
+	Steps() int
+	// Int returns the memory cell at the address as an int, false when it does not fit
+	Int(address int) (int, bool)
+}
+
+// WithTracer calls the tracer after every executed instruction. It may be given several times.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracers = append(o.tracers, t)
+	}
+}
+
+// trace reports the instruction that ran at ip to the tracers
+func (c *Machine[W, A]) trace(e Event) {
+	e.Step = c.steps
+	e.NextIP = c.ip
+	for _, t := range c.tracers {
+		t.Trace(c, e)
+	}
+}
+
+// accesses fills in the memory the instruction reads and writes, before it runs
+func (c *Machine[W, A]) accesses(e *Event) {
+	write := writesParam(e.Opcode)
+	e.Reads, e.Writes = c.accessBuf[0:0:3], c.accessBuf[3:3:4]
+	for pos := 0; pos < paramCounts[e.Opcode]; pos++ {
+		if pos == 1 && (e.Opcode == JMP_IF_TRUE || e.Opcode == JMP_IF_FALSE) && !e.Jumped {
+			// the target of a jump that is not taken is never read
+			continue
+		}
+		mode := e.Instruction / 100
+		for i := 0; i < pos; i++ {
+			mode /= 10
+		}
+		var address int
+		switch mode % 10 {
+		case POSITION_MODE:
+			address = c.int(c.Peek(e.IP + 1 + pos))
+		case RELATIVE_MODE:
+			address = e.RelativeBase + c.int(c.Peek(e.IP+1+pos))
+		default:
+			continue
+		}
+		if pos == write {
+			e.Writes = append(e.Writes, address)
+		} else {
+			e.Reads = append(e.Reads, address)
+		}
+	}
+}
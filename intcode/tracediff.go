@@ -0,0 +1,313 @@
+package intcode
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// TraceRecord is a line of a JSONL execution trace, one per executed instruction
+type TraceRecord struct {
+	Step         int          `json:"step"`
+	IP           int          `json:"ip"`
+	Instruction  int          `json:"instruction"`
+	RelativeBase int          `json:"rb"`
+	Writes       []TraceWrite `json:"writes,omitempty"`
+	Output       *int         `json:"output,omitempty"`
+}
+
+// TraceWrite is a memory cell an instruction wrote
+type TraceWrite struct {
+	Address int `json:"address"`
+	Value   int `json:"value"`
+}
+
+// equal reports whether two records describe the same instruction with the same effects
+func (r TraceRecord) equal(o TraceRecord) bool {
+	if r.IP != o.IP || r.Instruction != o.Instruction || r.RelativeBase != o.RelativeBase || len(r.Writes) != len(o.Writes) {
+		return false
+	}
+	for i := range r.Writes {
+		if r.Writes[i] != o.Writes[i] {
+			return false
+		}
+	}
+	if (r.Output == nil) != (o.Output == nil) {
+		return false
+	}
+	return r.Output == nil || *r.Output == *o.Output
+}
+
+func (r TraceRecord) String() string {
+	s := fmt.Sprintf("step %d ip %d instruction %d rb %d", r.Step, r.IP, r.Instruction, r.RelativeBase)
+	for _, w := range r.Writes {
+		s += fmt.Sprintf(" [%d]=%d", w.Address, w.Value)
+	}
+	if r.Output != nil {
+		s += fmt.Sprintf(" output %d", *r.Output)
+	}
+	return s
+}
+
+// JSONTracer writes a TraceRecord per executed instruction as a line of JSON. Add it to a computer with WithTracer.
+type JSONTracer struct {
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONTracer writes the trace to w
+func NewJSONTracer(w io.Writer) *JSONTracer {
+	return &JSONTracer{enc: json.NewEncoder(w)}
+}
+
+// Trace writes the record of the instruction
+func (t *JSONTracer) Trace(c Inspector, e Event) {
+	if t.err != nil {
+		return
+	}
+	r := TraceRecord{Step: e.Step, IP: e.IP, Instruction: e.Instruction, RelativeBase: e.RelativeBase}
+	for _, address := range e.Writes {
+		value, _ := c.Int(address)
+		r.Writes = append(r.Writes, TraceWrite{Address: address, Value: value})
+	}
+	if e.Opcode == OUTPUT {
+		value, _ := c.Int(e.IP + 1)
+		if len(e.Reads) > 0 {
+			value, _ = c.Int(e.Reads[0])
+		}
+		r.Output = &value
+	}
+	t.err = t.enc.Encode(r)
+}
+
+// Err returns the first error writing the trace
+func (t *JSONTracer) Err() error {
+	return t.err
+}
+
+// ReadTrace reads a JSONL trace
+func ReadTrace(r io.Reader) ([]TraceRecord, error) {
+	var records []TraceRecord
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; s.Scan(); line++ {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		var record TraceRecord
+		if err := json.Unmarshal(s.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, s.Err()
+}
+
+// resyncWindow is how far after a divergence DiffTraces looks for the traces to run the same instructions again
+const resyncWindow = 1000
+
+// resyncLength is how many instructions in a row must match for the traces to count as aligned again
+const resyncLength = 4
+
+// TraceDiff compares two traces, see DiffTraces
+type TraceDiff struct {
+	A, B []TraceRecord
+	// Divergence is the index of the first record that differs, -1 for identical traces
+	Divergence int
+	// ResyncA and ResyncB are the indexes where the traces run the same instructions again after the divergence,
+	// -1 when they do not within a thousand records
+	ResyncA, ResyncB int
+	// Context is the number of records shown around the divergence
+	Context int
+}
+
+// DiffTraces aligns two traces step by step and finds the first record that differs in the instruction, its relative
+// base, the memory it wrote or its output. A trace that ends early diverges where it ends.
+func DiffTraces(a, b []TraceRecord, context int) *TraceDiff {
+	d := &TraceDiff{A: a, B: b, Divergence: -1, ResyncA: -1, ResyncB: -1, Context: context}
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if !a[i].equal(b[i]) {
+			d.Divergence = i
+			break
+		}
+	}
+	if d.Divergence < 0 && len(a) != len(b) {
+		d.Divergence = n
+	}
+	if d.Divergence >= 0 {
+		d.ResyncA, d.ResyncB = resync(a, b, d.Divergence+1)
+	}
+	return d
+}
+
+// resync finds the closest pair of indexes from start on where both traces execute the same addresses for
+// resyncLength records
+func resync(a, b []TraceRecord, start int) (int, int) {
+	for dist := 0; dist < resyncWindow; dist++ {
+		// try every split of the distance between the traces, the nearest to an even split first
+		for da := 0; da <= dist; da++ {
+			i, j := start+da, start+dist-da
+			if sameAddresses(a, b, i, j) {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+func sameAddresses(a, b []TraceRecord, i, j int) bool {
+	if i+resyncLength > len(a) || j+resyncLength > len(b) {
+		return false
+	}
+	for k := 0; k < resyncLength; k++ {
+		if a[i+k].IP != b[j+k].IP || a[i+k].Instruction != b[j+k].Instruction {
+			return false
+		}
+	}
+	return true
+}
+
+// finalWrites returns the last value each address was written with
+func finalWrites(records []TraceRecord) map[int]int {
+	values := make(map[int]int)
+	for _, r := range records {
+		for _, w := range r.Writes {
+			values[w.Address] = w.Value
+		}
+	}
+	return values
+}
+
+func traceOutputs(records []TraceRecord) []int {
+	var out []int
+	for _, r := range records {
+		if r.Output != nil {
+			out = append(out, *r.Output)
+		}
+	}
+	return out
+}
+
+// maxListed is how many addresses or values the summary lists
+const maxListed = 10
+
+// Write reports the first divergence with the records around it side by side, then summarizes how the memory writes
+// and outputs after it differ
+func (d *TraceDiff) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if d.Divergence < 0 {
+		fmt.Fprintf(bw, "traces are identical, %d steps\n", len(d.A))
+		return bw.Flush()
+	}
+	fmt.Fprintf(bw, "traces diverge at record %d (a has %d records, b has %d)\n\n", d.Divergence, len(d.A), len(d.B))
+	from := d.Divergence - d.Context
+	if from < 0 {
+		from = 0
+	}
+	for i := from; i <= d.Divergence+d.Context; i++ {
+		if i >= len(d.A) && i >= len(d.B) {
+			break
+		}
+		mark := " "
+		if i == d.Divergence {
+			mark = ">"
+		}
+		fmt.Fprintf(bw, "%s a: %s\n%s b: %s\n", mark, recordText(d.A, i), mark, recordText(d.B, i))
+	}
+	if d.ResyncA >= 0 {
+		fmt.Fprintf(bw, "\nrealigned at ip %d after %d records in a and %d in b\n",
+			d.A[d.ResyncA].IP, d.ResyncA-d.Divergence, d.ResyncB-d.Divergence)
+	} else {
+		fmt.Fprintf(bw, "\nthe traces do not realign within %d records\n", resyncWindow)
+	}
+
+	// the records before the divergence are the same, so their effects are too
+	wa, wb := finalWrites(d.A[d.Divergence:]), finalWrites(d.B[d.Divergence:])
+	var differ, onlyA, onlyB []int
+	for address, va := range wa {
+		vb, ok := wb[address]
+		switch {
+		case !ok:
+			onlyA = append(onlyA, address)
+		case va != vb:
+			differ = append(differ, address)
+		}
+	}
+	for address := range wb {
+		if _, ok := wa[address]; !ok {
+			onlyB = append(onlyB, address)
+		}
+	}
+	sort.Ints(differ)
+	sort.Ints(onlyA)
+	sort.Ints(onlyB)
+	fmt.Fprintf(bw, "\nmemory writes after the divergence: %d addresses end with different values, %d only written by a, %d only by b\n",
+		len(differ), len(onlyA), len(onlyB))
+	for i, address := range differ {
+		if i == maxListed {
+			fmt.Fprintf(bw, "  ...\n")
+			break
+		}
+		fmt.Fprintf(bw, "  [%d] a=%d b=%d\n", address, wa[address], wb[address])
+	}
+	if len(onlyA) > 0 {
+		fmt.Fprintf(bw, "  only a: %s\n", listed(onlyA))
+	}
+	if len(onlyB) > 0 {
+		fmt.Fprintf(bw, "  only b: %s\n", listed(onlyB))
+	}
+
+	oa, ob := traceOutputs(d.A), traceOutputs(d.B)
+	first := -1
+	for i := 0; i < len(oa) || i < len(ob); i++ {
+		if i >= len(oa) || i >= len(ob) || oa[i] != ob[i] {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		fmt.Fprintf(bw, "outputs: the same %d values\n", len(oa))
+	} else {
+		fmt.Fprintf(bw, "outputs: a has %d values, b has %d, first difference at output %d\n  a: %s\n  b: %s\n",
+			len(oa), len(ob), first, listed(tail(oa, first)), listed(tail(ob, first)))
+	}
+	return bw.Flush()
+}
+
+func recordText(records []TraceRecord, i int) string {
+	if i >= len(records) {
+		return "(end of trace)"
+	}
+	return records[i].String()
+}
+
+func tail(values []int, from int) []int {
+	if from > len(values) {
+		return nil
+	}
+	return values[from:]
+}
+
+// listed formats the first values of a list
+func listed(values []int) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	s := ""
+	for i, v := range values {
+		if i == maxListed {
+			return s + " ..."
+		}
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprint(v)
+	}
+	return s
+}
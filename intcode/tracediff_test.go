@@ -0,0 +1,119 @@
+package intcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// traced runs the program and reads back its JSON trace
+func traced(t *testing.T, program []int, input ...int) []TraceRecord {
+	t.Helper()
+	var buf bytes.Buffer
+	tracer := NewJSONTracer(&buf)
+	c := NewComputer(program, WithTracer(tracer))
+	c.Send(input...)
+	c.CloseInput()
+	if _, err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracer.Err(); err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestDiffTraces(t *testing.T) {
+	// skip jumps over an output when the input is zero, then outputs four values
+	skip := []int{3, 20, 1006, 20, 7, 104, 9, 104, 1, 104, 2, 104, 3, 104, 4, 99}
+	five := []int{104, 1, 104, 2, 104, 3, 104, 4, 104, 5, 99}
+	patched := []int{104, 1, 104, 7, 104, 3, 104, 4, 104, 5, 99}
+	full := traced(t, five)
+
+	tests := []struct {
+		name                         string
+		a, b                         []TraceRecord
+		divergence, resyncA, resyncB int
+		// report are lines the written report must contain
+		report []string
+	}{
+		{"identical", traced(t, compare8, 8), traced(t, compare8, 8), -1, -1, -1,
+			[]string{"traces are identical, 7 steps"}},
+		{"other input", traced(t, compare8, 7), traced(t, compare8, 9), 0, 1, 1, []string{
+			"traces diverge at record 0 (a has 8 records, b has 10)",
+			"> a: step 0 ip 0 instruction 3 rb 0 [21]=7",
+			"> b: step 0 ip 0 instruction 3 rb 0 [21]=9",
+			"realigned at ip 2 after 1 records in a and 1 in b",
+			"  [20] a=0 b=1001",
+			"outputs: a has 1 values, b has 1, first difference at output 0",
+		}},
+		{"other path", traced(t, compare8, 8), traced(t, compare8, 9), 0, -1, -1, []string{
+			"the traces do not realign within 1000 records",
+			"  [20] a=1000 b=1001",
+		}},
+		{"extra instruction", traced(t, skip, 0), traced(t, skip, 1), 0, 2, 3, []string{
+			"realigned at ip 7 after 2 records in a and 3 in b",
+			"outputs: a has 4 values, b has 5, first difference at output 0",
+			"  a: 1 2 3 4",
+			"  b: 9 1 2 3 4",
+		}},
+		{"patched output", full, traced(t, patched), 1, 2, 2, []string{
+			"> a: step 1 ip 2 instruction 104 rb 0 output 2",
+			"> b: step 1 ip 2 instruction 104 rb 0 output 7",
+			"memory writes after the divergence: 0 addresses end with different values, 0 only written by a, 0 only by b",
+		}},
+		{"truncated", full, full[:3], 3, -1, -1, []string{
+			"traces diverge at record 3 (a has 6 records, b has 3)",
+			"> b: (end of trace)",
+			"outputs: a has 5 values, b has 3, first difference at output 3",
+			"  b: -",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DiffTraces(tt.a, tt.b, 1)
+			if d.Divergence != tt.divergence || d.ResyncA != tt.resyncA || d.ResyncB != tt.resyncB {
+				t.Errorf("diverged at %d and realigned at %d and %d, want %d, %d and %d",
+					d.Divergence, d.ResyncA, d.ResyncB, tt.divergence, tt.resyncA, tt.resyncB)
+			}
+			var out strings.Builder
+			if err := d.Write(&out); err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range tt.report {
+				if !strings.Contains(out.String(), line+"\n") {
+					t.Errorf("report has no line %q:\n%s", line, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestReadTrace(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    int
+		wantErr string
+	}{
+		{"records", "{\"step\":0,\"ip\":0,\"instruction\":104,\"rb\":0,\"output\":1}\n\n{\"step\":1,\"ip\":2,\"instruction\":99,\"rb\":0}\n", 2, ""},
+		{"empty", "", 0, ""},
+		{"broken", "{\"step\":0}\n{\"step\":\n", 0, "line 2: "},
+	}
+	for _, tt := range tests {
+		records, err := ReadTrace(strings.NewReader(tt.in))
+		if tt.wantErr != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || len(records) != tt.want {
+			t.Errorf("%s: read %v, %v", tt.name, records, err)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package intcode
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go.uber.org/zap"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// minNativeMemory is the memory size transpiled code may index without a bounds check
+const minNativeMemory = 3000
+
+// Compiled is a program transpiled to Go, see Transpile and Computer.RunNative
+type Compiled struct {
+	// Program is the program the code was transpiled from
+	Program []int
+	// Code marks the memory cells that hold translated instructions
+	Code []bool
+	// Run executes translated instructions from n.IP until it reaches one it does not handle
+	Run func(n *Native)
+}
+
+// Native is the state transpiled code runs on. It shares memory with the computer.
+type Native struct {
+	Mem []int
+	IP  int
+	RB  int
+	// Modified is set when the code wrote to a translated instruction, the rest of the run is interpreted
+	Modified bool
+
+	c *Computer
+}
+
+// Grow makes the address part of memory and returns the memory
+func (n *Native) Grow(address int) []int {
+	n.c.cell(address)
+	n.Mem = n.c.memory
+	return n.Mem
+}
+
+// matches reports whether the translated instructions are still what the computer has in memory
+func (p *Compiled) matches(c *Computer) bool {
+	for address, code := range p.Code {
+		if code && (address >= len(c.memory) || c.memory[address] != p.Program[address]) {
+			return false
+		}
+	}
+	return true
+}
+
+// RunNative runs like Run but executes translated instructions as Go code. Input, output and halting are left to the
+// interpreter, as is everything after the program modified a translated instruction. Computers whose memory no longer
+// matches the compiled program or that have limits, tracers or a spec level run on the interpreter only. The steps
+// and maxima of the result only count interpreted instructions. Translated code works on int words, machines of other
+// word types are interpreted. Pause and State wait for the translated code to hand an instruction back.
+func (c *Machine[W, A]) RunNative(p *Compiled) (RunResult, error) {
+	start, steps, outputs := time.Now(), c.steps, c.outputs
+	err := c.runNative(p)
+	return c.result(start, steps, outputs, err), err
+}
+
+// runNative executes until the computer stops, see RunNative
+func (c *Machine[W, A]) runNative(p *Compiled) (err error) {
+	ic, ok := any(c).(*Computer)
+	if !ok || c.limits != (Limits{}) || c.tracers != nil || c.spec != SpecDay09 || !p.matches(ic) {
+		c.logs.native.Debug("running on the interpreter", zap.Bool("limits", c.limits != (Limits{})), zap.Int("tracers", len(c.tracers)))
+		return c.run()
+	}
+	defer ic.recoverFault(&err)
+	// growing memory for the translated code is no access of the program
+	maxAddress := ic.maxAddress
+	ic.cell(minNativeMemory - 1)
+	ic.maxAddress = maxAddress
+
+	n := &Native{c: ic}
+	for {
+		ic.ctl.enter()
+		n.Mem, n.IP, n.RB = ic.memory, ic.ip, ic.relativeBase
+		p.Run(n)
+		ic.ip, ic.relativeBase = n.IP, n.RB
+		ic.ctl.leave()
+		if n.Modified {
+			ic.logs.native.Debug("program modified translated code", zap.Int("instructionPointer", ic.ip))
+			return ic.run()
+		}
+		if ic.E() {
+			return ic.Err()
+		}
+	}
+}
+
+// TranspileOptions name the generated code
+type TranspileOptions struct {
+	// Package of the generated file, main when empty
+	Package string
+	// Var is the name of the *intcode.Compiled variable, Program when empty
+	Var string
+	// Optimize translates the instructions as rewritten by Optimize, the program itself is kept. The optimized
+	// program must behave like the original for every one of Inputs, see VerifyEquivalent.
+	Optimize bool
+	// Inputs are the verification runs of Optimize, a single run without input when empty
+	Inputs [][]int
+	// Limits stop the verification runs, MaxSteps is 10000000 when they are zero
+	Limits Limits
+}
+
+// Transpile writes Go source for the program. Instructions reachable from address zero, from immediate jump targets
+// and from return addresses pushed as immediates become straight-line code with a label each, so a jump to a
+// translated instruction is a goto. Anything else, like input and output, returns to the interpreter.
+func Transpile(w io.Writer, program []int, opts TranspileOptions) error {
+	if opts.Package == "" {
+		opts.Package = "main"
+	}
+	if opts.Var == "" {
+		opts.Var = "Program"
+	}
+
+	insts := translatable(program)
+	if opts.Optimize {
+		// optimized instructions keep their addresses and lengths
+		optimized, _ := Optimize(program)
+		inputs, limits := opts.Inputs, opts.Limits
+		if len(inputs) == 0 {
+			inputs = [][]int{nil}
+		}
+		if limits == (Limits{}) {
+			limits.MaxSteps = 10000000
+		}
+		if err := VerifyEquivalent(program, optimized, inputs, limits); err != nil {
+			return fmt.Errorf("optimized program differs: %w", err)
+		}
+		for address := range insts {
+			inst, err := Decode(optimized, address)
+			if err != nil {
+				return fmt.Errorf("decode optimized instruction: %v", err)
+			}
+			insts[address] = inst
+		}
+	}
+	code := make([]bool, len(program))
+	addresses := make([]int, 0, len(insts))
+	for address, inst := range insts {
+		addresses = append(addresses, address)
+		for i := 0; i < inst.Len(); i++ {
+			code[address+i] = true
+		}
+	}
+	sort.Ints(addresses)
+
+	g := &generator{name: opts.Var, code: code, insts: insts}
+	g.printf("// Code generated by intcode transpile. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", opts.Package)
+	g.printf("import \"github.com/ljdelight/adventOfCode-2019/intcode\"\n\n")
+	g.printf("// %s is a transpiled program, run it with intcode.Computer.RunNative\n", opts.Var)
+	g.printf("var %s = &intcode.Compiled{\n", opts.Var)
+	g.printf("Program: %s,\n", intSlice(program))
+	g.printf("Code: %sCode,\n", opts.Var)
+	g.printf("Run: %sRun,\n", opts.Var)
+	g.printf("}\n\n")
+	g.printf("var %sCode = %s\n\n", opts.Var, boolSlice(code))
+
+	g.printf("func %sRun(n *intcode.Native) {\n", opts.Var)
+	g.printf("mem, ip, rb := n.Mem, n.IP, n.RB\n")
+	g.printf("code := %sCode\n", opts.Var)
+	g.printf("var a, v int\n")
+	g.printf("_, _, _ = a, v, code\n")
+	head := g.buf.Len()
+
+	for i, address := range addresses {
+		inst := insts[address]
+		g.printf("L%d: // %s\n", address, inst)
+		end := g.instruction(inst)
+		next := address + inst.Len()
+		if !end && (i+1 == len(addresses) || addresses[i+1] != next) {
+			// the next instruction was not translated
+			g.printf("ip = %d\ngoto dispatch\n", next)
+			g.dispatch = true
+		}
+	}
+	g.printf("}\n\n")
+
+	// the dispatch switch goes in front of the instructions, its label only when some code jumps back to it
+	body := append([]byte(nil), g.buf.Bytes()[head:]...)
+	g.buf.Truncate(head)
+	if g.dispatch {
+		g.printf("dispatch:\n")
+	}
+	g.printf("switch ip {\n")
+	for _, address := range addresses {
+		g.printf("case %d:\ngoto L%d\n", address, address)
+	}
+	g.printf("default:\nn.IP, n.RB = ip, rb\nreturn\n}\n")
+	g.buf.Write(body)
+
+	g.printf("func %sLoad(mem []int, a int) int {\nif a < len(mem) {\nreturn mem[a]\n}\nreturn 0\n}\n", opts.Var)
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated code: %v", err)
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(src); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// translatable finds the instructions to translate by following the control flow
+func translatable(program []int) map[int]*Instruction {
+	// a return address is the address after a jump that the program uses as an immediate value
+	afterJump := make(map[int]bool)
+	for address := 0; address < len(program); address++ {
+		if inst, err := Decode(program, address); err == nil && (inst.Opcode == JMP_IF_TRUE || inst.Opcode == JMP_IF_FALSE) {
+			afterJump[address+inst.Len()] = true
+		}
+	}
+
+	insts := make(map[int]*Instruction)
+	work := []int{0}
+	for address := 0; address < len(program); address++ {
+		if inst, err := Decode(program, address); err == nil {
+			for p, param := range inst.Params {
+				if inst.Modes[p] == IMMEDIATE_MODE && afterJump[param] {
+					work = append(work, param)
+				}
+			}
+		}
+	}
+
+	for len(work) > 0 {
+		address := work[len(work)-1]
+		work = work[:len(work)-1]
+		for address >= 0 && address < len(program) {
+			if _, done := insts[address]; done {
+				break
+			}
+			inst, err := Decode(program, address)
+			if err != nil {
+				break
+			}
+			insts[address] = inst
+
+			if inst.Opcode == HALT {
+				break
+			}
+			if inst.Opcode == JMP_IF_TRUE || inst.Opcode == JMP_IF_FALSE {
+				if inst.Modes[1] == IMMEDIATE_MODE {
+					work = append(work, inst.Params[1])
+				}
+				if inst.Modes[0] == IMMEDIATE_MODE && (inst.Params[0] != 0) == (inst.Opcode == JMP_IF_TRUE) {
+					// always taken
+					break
+				}
+			}
+			address += inst.Len()
+		}
+	}
+
+	// instructions overlapping an earlier one come from jumps into operands, those are left to the interpreter
+	var addresses []int
+	for address := range insts {
+		addresses = append(addresses, address)
+	}
+	sort.Ints(addresses)
+	end := 0
+	for _, address := range addresses {
+		if address < end {
+			delete(insts, address)
+			continue
+		}
+		end = address + insts[address].Len()
+	}
+	return insts
+}
+
+type generator struct {
+	// name prefixes the generated functions
+	name  string
+	buf   bytes.Buffer
+	code  []bool
+	insts map[int]*Instruction
+	// dispatch is set once the code jumps to the dispatch switch
+	dispatch bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// operand returns the Go expression reading the parameter
+func (g *generator) operand(inst *Instruction, p int) string {
+	param := inst.Params[p]
+	switch inst.Modes[p] {
+	case IMMEDIATE_MODE:
+		return fmt.Sprintf("%d", param)
+	case POSITION_MODE:
+		if param >= 0 && param < minNativeMemory {
+			return fmt.Sprintf("mem[%d]", param)
+		}
+		return fmt.Sprintf("%sLoad(mem, %d)", g.name, param)
+	default:
+		return fmt.Sprintf("%sLoad(mem, rb%+d)", g.name, param)
+	}
+}
+
+// store writes v to the parameter. Writes that may hit a translated instruction check whether they change it.
+func (g *generator) store(inst *Instruction, p int) {
+	next := inst.Address + inst.Len()
+	param := inst.Params[p]
+	if inst.Modes[p] == POSITION_MODE && param >= 0 && param < minNativeMemory {
+		if param < len(g.code) && g.code[param] {
+			g.printf("if mem[%d] != v {\nmem[%d] = v\nn.IP, n.RB, n.Modified = %d, rb, true\nreturn\n}\n", param, param, next)
+			return
+		}
+		g.printf("mem[%d] = v\n", param)
+		return
+	}
+
+	if inst.Modes[p] == POSITION_MODE {
+		g.printf("a = %d\n", param)
+	} else {
+		g.printf("a = rb%+d\n", param)
+	}
+	g.printf("if a >= len(mem) {\nmem = n.Grow(a)\n}\n")
+	g.printf("if a < len(code) && code[a] && mem[a] != v {\nmem[a] = v\nn.IP, n.RB, n.Modified = %d, rb, true\nreturn\n}\n", next)
+	g.printf("mem[a] = v\n")
+}
+
+// jump transfers control to the target of a jump instruction
+func (g *generator) jump(inst *Instruction) {
+	if inst.Modes[1] == IMMEDIATE_MODE {
+		if _, ok := g.insts[inst.Params[1]]; ok {
+			g.printf("goto L%d\n", inst.Params[1])
+			return
+		}
+	}
+	g.printf("ip = %s\ngoto dispatch\n", g.operand(inst, 1))
+	g.dispatch = true
+}
+
+// instruction emits the code of one instruction and reports whether control never falls through to the next
+func (g *generator) instruction(inst *Instruction) bool {
+	switch inst.Opcode {
+	case ADD:
+		g.printf("v = %s + %s\n", g.operand(inst, 0), g.operand(inst, 1))
+		g.store(inst, 2)
+	case MUL:
+		g.printf("v = %s * %s\n", g.operand(inst, 0), g.operand(inst, 1))
+		g.store(inst, 2)
+	case LESS_THAN, EQUALS:
+		op := "<"
+		if inst.Opcode == EQUALS {
+			op = "=="
+		}
+		g.printf("v = 0\nif %s %s %s {\nv = 1\n}\n", g.operand(inst, 0), op, g.operand(inst, 1))
+		g.store(inst, 2)
+	case JMP_IF_TRUE, JMP_IF_FALSE:
+		op := "!="
+		if inst.Opcode == JMP_IF_FALSE {
+			op = "=="
+		}
+		if inst.Modes[0] == IMMEDIATE_MODE {
+			if (inst.Params[0] != 0) == (inst.Opcode == JMP_IF_TRUE) {
+				g.jump(inst)
+				return true
+			}
+			// never taken
+			return false
+		}
+		g.printf("if %s %s 0 {\n", g.operand(inst, 0), op)
+		g.jump(inst)
+		g.printf("}\n")
+	case ADJ_RELATIVE_BASE:
+		g.printf("rb += %s\n", g.operand(inst, 0))
+	default:
+		// input, output and halt run on the interpreter
+		g.printf("n.IP, n.RB = %d, rb\nreturn\n", inst.Address)
+		return true
+	}
+	return false
+}
+
+func intSlice(values []int) string {
+	parts := make([]string, len(values))
+	for i, val := range values {
+		parts[i] = fmt.Sprint(val)
+	}
+	return "[]int{" + strings.Join(parts, ", ") + "}"
+}
+
+func boolSlice(values []bool) string {
+	parts := make([]string, len(values))
+	for i, val := range values {
+		parts[i] = fmt.Sprint(val)
+	}
+	return "[]bool{" + strings.Join(parts, ", ") + "}"
+}
@@ -0,0 +1,141 @@
+package intcode
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Arithmetic implements the operations on the words of a Machine. The implementations are empty structs, so the
+// arithmetic is chosen by the type alone, see IntWords, Int32Words, Int64Words and BigWords.
+type Arithmetic[W any] interface {
+	Add(a, b W) W
+	Mul(a, b W) W
+	Less(a, b W) bool
+	Equal(a, b W) bool
+	IsZero(w W) bool
+	// FromInt converts an int, it panics with an OverflowError when the value does not fit a word
+	FromInt(v int) W
+	// ToInt converts a word to an int, false when it does not fit
+	ToInt(w W) (int, bool)
+	// Size is the number of bytes a word takes in memory, not counting the heap data of big integers
+	Size() int
+}
+
+// OverflowError is the fault of an instruction whose result does not fit the word type
+type OverflowError struct {
+	Op   string
+	A, B string
+	// Word names the word type
+	Word string
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("%s %s %s overflows %s", e.A, e.Op, e.B, e.Word)
+}
+
+// IntWords is the arithmetic of Go int words, which wrap around like Go ints
+type IntWords struct{}
+
+func (IntWords) Add(a, b int) int        { return a + b }
+func (IntWords) Mul(a, b int) int        { return a * b }
+func (IntWords) Less(a, b int) bool      { return a < b }
+func (IntWords) Equal(a, b int) bool     { return a == b }
+func (IntWords) IsZero(w int) bool       { return w == 0 }
+func (IntWords) FromInt(v int) int       { return v }
+func (IntWords) ToInt(w int) (int, bool) { return w, true }
+func (IntWords) Size() int               { return 8 }
+
+// Int64Words is the arithmetic of int64 words, which wrap around like Go ints
+type Int64Words struct{}
+
+func (Int64Words) Add(a, b int64) int64      { return a + b }
+func (Int64Words) Mul(a, b int64) int64      { return a * b }
+func (Int64Words) Less(a, b int64) bool      { return a < b }
+func (Int64Words) Equal(a, b int64) bool     { return a == b }
+func (Int64Words) IsZero(w int64) bool       { return w == 0 }
+func (Int64Words) FromInt(v int) int64       { return int64(v) }
+func (Int64Words) ToInt(w int64) (int, bool) { return int(w), int64(int(w)) == w }
+func (Int64Words) Size() int                 { return 8 }
+
+// Int32Words is the arithmetic of int32 words, for half the memory. Results that do not fit fault with an
+// OverflowError instead of wrapping around.
+type Int32Words struct{}
+
+func (Int32Words) Add(a, b int32) int32 {
+	return checkInt32("+", a, b, int64(a)+int64(b))
+}
+
+func (Int32Words) Mul(a, b int32) int32 {
+	return checkInt32("*", a, b, int64(a)*int64(b))
+}
+
+func checkInt32(op string, a, b int32, r int64) int32 {
+	if r < math.MinInt32 || r > math.MaxInt32 {
+		panic(&OverflowError{Op: op, A: fmt.Sprint(a), B: fmt.Sprint(b), Word: "int32"})
+	}
+	return int32(r)
+}
+
+func (Int32Words) Less(a, b int32) bool  { return a < b }
+func (Int32Words) Equal(a, b int32) bool { return a == b }
+func (Int32Words) IsZero(w int32) bool   { return w == 0 }
+
+func (Int32Words) FromInt(v int) int32 {
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		panic(&OverflowError{Op: "as", A: fmt.Sprint(v), B: "word", Word: "int32"})
+	}
+	return int32(v)
+}
+
+func (Int32Words) ToInt(w int32) (int, bool) { return int(w), true }
+func (Int32Words) Size() int                 { return 4 }
+
+// BigWords is the arithmetic of arbitrary precision words. A nil word is zero and words are never modified in
+// place, so memory cells may share them.
+type BigWords struct{}
+
+var bigZero = new(big.Int)
+
+func bigOf(w *big.Int) *big.Int {
+	if w == nil {
+		return bigZero
+	}
+	return w
+}
+
+func (BigWords) Add(a, b *big.Int) *big.Int { return new(big.Int).Add(bigOf(a), bigOf(b)) }
+func (BigWords) Mul(a, b *big.Int) *big.Int { return new(big.Int).Mul(bigOf(a), bigOf(b)) }
+func (BigWords) Less(a, b *big.Int) bool    { return bigOf(a).Cmp(bigOf(b)) < 0 }
+func (BigWords) Equal(a, b *big.Int) bool   { return bigOf(a).Cmp(bigOf(b)) == 0 }
+func (BigWords) IsZero(w *big.Int) bool     { return w == nil || w.Sign() == 0 }
+func (BigWords) FromInt(v int) *big.Int     { return big.NewInt(int64(v)) }
+func (BigWords) Size() int                  { return 8 }
+
+func (BigWords) ToInt(w *big.Int) (int, bool) {
+	w = bigOf(w)
+	if !w.IsInt64() {
+		return 0, false
+	}
+	v := w.Int64()
+	return int(v), int64(int(v)) == v
+}
+
+// Words converts a program to the word type. It fails for values that do not fit.
+func Words[W any, A Arithmetic[W]](program []int) (words []W, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			overflow, ok := r.(*OverflowError)
+			if !ok {
+				panic(r)
+			}
+			words, err = nil, overflow
+		}
+	}()
+	var a A
+	words = make([]W, len(program))
+	for i, v := range program {
+		words[i] = a.FromInt(v)
+	}
+	return words, nil
+}